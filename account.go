@@ -0,0 +1,320 @@
+package overflow
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/onflow/flowkit/v2/output"
+	"github.com/pkg/errors"
+)
+
+// Accounts
+//
+// Overflow can create accounts at runtime and register them under a logical name so they work like accounts in flow.json
+
+// a type representing setting an option in the account builder
+type OverflowAccountOption func(*OverflowAccountBuilder)
+
+// a type representing the accumulated state when creating an account
+type OverflowAccountBuilder struct {
+	// the private key to use, if nil a new key pair is generated
+	PrivateKey crypto.PrivateKey
+
+	// the signature algorithm used when generating a key
+	SigAlgo crypto.SignatureAlgorithm
+
+	// the hash algorithm of the key
+	HashAlgo crypto.HashAlgorithm
+
+	// the weight of the key
+	Weight int
+
+	// the amount of flow to mint to the new account, only possible on emulator
+	FlowAmount float64
+
+	// set if the flow amount is sent in, keys can not be added with it
+	flowAmountSet bool
+}
+
+// use the given private key for the account instead of generating one
+func WithAccountPrivateKey(key crypto.PrivateKey) OverflowAccountOption {
+	return func(oab *OverflowAccountBuilder) {
+		oab.PrivateKey = key
+		oab.SigAlgo = key.Algorithm()
+	}
+}
+
+// set the signature algorithm of the generated key, default ECDSA_P256
+func WithAccountSigAlgo(sigAlgo crypto.SignatureAlgorithm) OverflowAccountOption {
+	return func(oab *OverflowAccountBuilder) {
+		oab.SigAlgo = sigAlgo
+	}
+}
+
+// set the hash algorithm of the key, default SHA3_256
+func WithAccountHashAlgo(hashAlgo crypto.HashAlgorithm) OverflowAccountOption {
+	return func(oab *OverflowAccountBuilder) {
+		oab.HashAlgo = hashAlgo
+	}
+}
+
+// set the weight of the key, default 1000
+func WithAccountKeyWeight(weight int) OverflowAccountOption {
+	return func(oab *OverflowAccountBuilder) {
+		oab.Weight = weight
+	}
+}
+
+// set the amount of flow to mint to the account, default is the amount for new users. Set to 0 to not mint anything
+func WithAccountFlowAmount(amount float64) OverflowAccountOption {
+	return func(oab *OverflowAccountBuilder) {
+		oab.FlowAmount = amount
+		oab.flowAmountSet = true
+	}
+}
+
+func (o *OverflowState) newAccountBuilder(opts []OverflowAccountOption) *OverflowAccountBuilder {
+	oab := &OverflowAccountBuilder{
+		SigAlgo:    crypto.ECDSA_P256,
+		HashAlgo:   crypto.SHA3_256,
+		Weight:     1000,
+		FlowAmount: o.NewUserFlowAmount,
+	}
+	for _, opt := range opts {
+		opt(oab)
+	}
+	return oab
+}
+
+// the private key that is sent in or a newly generated one
+func (oab *OverflowAccountBuilder) privateKey() (crypto.PrivateKey, error) {
+	if oab.PrivateKey != nil {
+		return oab.PrivateKey, nil
+	}
+	seed := make([]byte, crypto.MinSeedLength)
+	_, err := rand.Read(seed)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.GeneratePrivateKey(oab.SigAlgo, seed)
+}
+
+// CreateAccount will create an account with the given logical name and panic on errors
+func (o *OverflowState) CreateAccount(name string, opts ...OverflowAccountOption) *accounts.Account {
+	account, err := o.CreateAccountE(context.Background(), name, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return account
+}
+
+// CreateAccountE will create an account on chain and register it in State with the given logical name
+// After this the name can be used in WithSigner, Address and when resolving arguments
+func (o *OverflowState) CreateAccountE(ctx context.Context, name string, opts ...OverflowAccountOption) (*accounts.Account, error) {
+	oab := o.newAccountBuilder(opts)
+
+	accountName := o.accountName(name)
+	if _, err := o.AccountE(name); err == nil {
+		return nil, fmt.Errorf("account with name %s already exist", accountName)
+	}
+
+	privateKey, err := oab.privateKey()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not generate key for account %s", accountName)
+	}
+
+	signerAccount, err := o.State.Accounts().ByName(o.ServiceAccountName())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []accounts.PublicKey{{
+		Public:   privateKey.PublicKey(),
+		Weight:   oab.Weight,
+		SigAlgo:  privateKey.Algorithm(),
+		HashAlgo: oab.HashAlgo,
+	}}
+
+	o.Logger.Info(fmt.Sprintf("Creating account %s", accountName))
+	unlock := o.lockInteraction(false)
+	flowAccount, _, err := o.Flowkit.CreateAccount(ctx, signerAccount, keys)
+	unlock()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create account %s", accountName)
+	}
+
+	account := &accounts.Account{
+		Name:    accountName,
+		Address: flowAccount.Address,
+		Key:     accounts.NewHexKeyFromPrivateKey(0, oab.HashAlgo, privateKey),
+	}
+	o.accountMutex.Lock()
+	o.State.Accounts().AddOrUpdate(account)
+	o.accountMutex.Unlock()
+	o.logAccountCreated(name, account.Address.HexWithPrefix())
+
+	messages := []string{
+		fmt.Sprintf("%v", emoji.Person),
+		"Created account:",
+		accountName,
+		"with address:",
+		account.Address.String(),
+	}
+
+	if o.Network.Name == emulatorValue && oab.FlowAmount != 0.0 {
+		err := o.mintFlowTokens(account.Address.String(), oab.FlowAmount)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not mint flow tokens to %s", accountName)
+		}
+		messages = append(messages, "with flow:", fmt.Sprintf("%.2f", oab.FlowAmount))
+	}
+
+	if o.PrintOptions != nil && o.LogLevel == output.NoneLog {
+		o.printOutput(accountName, func(w io.Writer) { fmt.Fprintln(w, strings.Join(messages, " ")) })
+	}
+
+	return account, nil
+}
+
+// AddAccountKey adds a key to the account with the given name and panic on errors
+func (o *OverflowState) AddAccountKey(name string, opts ...OverflowAccountOption) *accounts.HexKey {
+	key, err := o.AddAccountKeyE(context.Background(), name, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// AddAccountKeyE adds a key to the account with the given name, the key is configured with the same options as when creating an account except WithAccountFlowAmount
+// The returned key has the index it got on chain and can be used to sign with WithSignerKey
+func (o *OverflowState) AddAccountKeyE(ctx context.Context, name string, opts ...OverflowAccountOption) (*accounts.HexKey, error) {
+	oab := o.newAccountBuilder(opts)
+	if oab.flowAmountSet {
+		return nil, fmt.Errorf("can not mint flow when adding a key to account %s", name)
+	}
+
+	privateKey, err := oab.privateKey()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not generate key for account %s", name)
+	}
+
+	sigAlgo, err := cadenceSignatureAlgorithm(privateKey.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+
+	hashAlgo, err := cadenceHashAlgorithm(oab.HashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	res := o.Tx(`
+transaction(publicKey: String, signatureAlgorithm: UInt8, hashAlgorithm: UInt8, weight: UFix64) {
+	prepare(signer: auth(AddKey) &Account) {
+		let key = PublicKey(
+			publicKey: publicKey.decodeHex(),
+			signatureAlgorithm: SignatureAlgorithm(rawValue: signatureAlgorithm)!
+		)
+		signer.keys.add(publicKey: key, hashAlgorithm: HashAlgorithm(rawValue: hashAlgorithm)!, weight: weight)
+	}
+}
+`,
+		WithContext(ctx),
+		WithSigner(name),
+		WithArg("publicKey", strings.TrimPrefix(privateKey.PublicKey().String(), "0x")),
+		WithArg("signatureAlgorithm", cadence.UInt8(sigAlgo)),
+		WithArg("hashAlgorithm", cadence.UInt8(hashAlgo)),
+		WithArg("weight", float64(oab.Weight)),
+		WithName(fmt.Sprintf("Add key to %s", name)),
+		WithoutLog(),
+	)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+
+	// the index is read from the event of this transaction, other keys can be added to the account at the same time
+	added := res.Events["flow.AccountKeyAdded"]
+	if len(added) != 1 {
+		return nil, fmt.Errorf("could not find the index of the key added to account %s", name)
+	}
+	index, err := strconv.Atoi(fmt.Sprint(added[0].Fields["keyIndex"]))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the index of the key added to account %s", name)
+	}
+
+	return accounts.NewHexKeyFromPrivateKey(index, oab.HashAlgo, privateKey), nil
+}
+
+// RevokeAccountKey revokes the key with the given index on the account and panic on errors
+func (o *OverflowState) RevokeAccountKey(name string, index int) {
+	err := o.RevokeAccountKeyE(context.Background(), name, index)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RevokeAccountKeyE revokes the key with the given index on the account with the given name
+func (o *OverflowState) RevokeAccountKeyE(ctx context.Context, name string, index int) error {
+	res := o.Tx(`
+transaction(keyIndex: Int) {
+	prepare(signer: auth(RevokeKey) &Account) {
+		signer.keys.revoke(keyIndex: keyIndex) ?? panic("no key with index ".concat(keyIndex.toString()))
+	}
+}
+`,
+		WithContext(ctx),
+		WithSigner(name),
+		WithArg("keyIndex", index),
+		WithName(fmt.Sprintf("Revoke key %d on %s", index, name)),
+		WithoutLog(),
+	)
+	return res.Err
+}
+
+// AccountKeys lists all the keys on the account with the given name and panic on errors
+func (o *OverflowState) AccountKeys(name string) []*flow.AccountKey {
+	keys, err := o.AccountKeysE(context.Background(), name)
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+// AccountKeysE lists all the keys on the account with the given name including sequence numbers and if they are revoked
+func (o *OverflowState) AccountKeysE(ctx context.Context, name string) ([]*flow.AccountKey, error) {
+	account, err := o.GetAccount(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return account.Keys, nil
+}
+
+// the raw value of the signature algorithm in cadence
+func cadenceSignatureAlgorithm(sigAlgo crypto.SignatureAlgorithm) (uint8, error) {
+	for _, algo := range sema.SignatureAlgorithms {
+		if algo.Name() == sigAlgo.String() {
+			return algo.RawValue(), nil
+		}
+	}
+	return 0, fmt.Errorf("signature algorithm %s is not supported", sigAlgo)
+}
+
+// the raw value of the hash algorithm in cadence
+func cadenceHashAlgorithm(hashAlgo crypto.HashAlgorithm) (uint8, error) {
+	for _, algo := range sema.HashAlgorithms {
+		if algo.Name() == hashAlgo.String() {
+			return algo.RawValue(), nil
+		}
+	}
+	return 0, fmt.Errorf("hash algorithm %s is not supported", hashAlgo)
+}
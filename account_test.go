@@ -2,8 +2,11 @@ package overflow
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	"github.com/onflow/flow-go-sdk/crypto"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -62,3 +65,104 @@ func TestGetAccount(t *testing.T) {
 
 	})
 }
+
+func TestCreateAccount(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	t.Run("Should create account and register name", func(t *testing.T) {
+		account, err := o.CreateAccountE(context.Background(), "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "emulator-alice", account.Name)
+		assert.Equal(t, fmt.Sprintf("0x%s", account.Address.Hex()), o.Address("alice"))
+
+		o.Tx("sendFlow",
+			WithSigner("alice"),
+			WithArg("amount", 1.0),
+			WithArg("to", "first"),
+		).AssertSuccess(t)
+	})
+
+	t.Run("Should create many accounts", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			o.CreateAccount(fmt.Sprintf("user%d", i), WithAccountFlowAmount(0.0))
+		}
+		assert.Equal(t, o.Address("user4"), o.Script("test", WithArg("account", "user4")).Output)
+	})
+
+	t.Run("Should create account with given key and weight", func(t *testing.T) {
+		seed := make([]byte, crypto.MinSeedLength)
+		pk, err := crypto.GeneratePrivateKey(crypto.ECDSA_secp256k1, seed)
+		require.NoError(t, err)
+
+		o.CreateAccount("bob", WithAccountPrivateKey(pk), WithAccountHashAlgo(crypto.SHA2_256), WithAccountKeyWeight(500))
+		account, err := o.GetAccount(context.Background(), "bob")
+		require.NoError(t, err)
+		assert.Equal(t, 500, account.Keys[0].Weight)
+		assert.Equal(t, crypto.ECDSA_secp256k1, account.Keys[0].SigAlgo)
+		assert.Equal(t, crypto.SHA2_256, account.Keys[0].HashAlgo)
+	})
+
+	t.Run("Should fail if account name exist", func(t *testing.T) {
+		_, err := o.CreateAccountE(context.Background(), "first")
+		assert.ErrorContains(t, err, "account with name emulator-first already exist")
+	})
+}
+
+func TestAccountKeys(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+	o.CreateAccount("carol")
+
+	sendFlow := func(opts ...OverflowInteractionOption) *OverflowResult {
+		return o.Tx("sendFlow", append([]OverflowInteractionOption{
+			WithArg("amount", 1.0),
+			WithArg("to", "first"),
+		}, opts...)...)
+	}
+
+	t.Run("Should add key and sign with it", func(t *testing.T) {
+		key := o.AddAccountKey("carol", WithAccountSigAlgo(crypto.ECDSA_secp256k1), WithAccountHashAlgo(crypto.SHA2_256))
+		assert.Equal(t, 1, key.Index())
+
+		keys := o.AccountKeys("carol")
+		require.Len(t, keys, 2)
+		assert.Equal(t, crypto.ECDSA_secp256k1, keys[1].SigAlgo)
+		assert.Equal(t, crypto.SHA2_256, keys[1].HashAlgo)
+		assert.Equal(t, 1000, keys[1].Weight)
+		assert.Equal(t, uint64(0), keys[1].SequenceNumber)
+
+		sendFlow(WithSignerKey("carol", key)).AssertSuccess(t)
+		assert.Equal(t, uint64(1), o.AccountKeys("carol")[1].SequenceNumber)
+	})
+
+	t.Run("Should sign with key index of the same private key", func(t *testing.T) {
+		account, err := o.AccountE("carol")
+		require.NoError(t, err)
+		pk, err := account.Key.PrivateKey()
+		require.NoError(t, err)
+
+		key := o.AddAccountKey("carol", WithAccountPrivateKey(*pk))
+		assert.Equal(t, 2, key.Index())
+		sendFlow(WithSignerKeyIndex("carol", 2)).AssertSuccess(t)
+	})
+
+	t.Run("Should not mint flow when adding a key", func(t *testing.T) {
+		_, err := o.AddAccountKeyE(context.Background(), "carol", WithAccountFlowAmount(10.0))
+		assert.ErrorContains(t, err, "can not mint flow when adding a key to account carol")
+		assert.Len(t, o.AccountKeys("carol"), 3)
+	})
+
+	t.Run("Should revoke key", func(t *testing.T) {
+		o.RevokeAccountKey("carol", 2)
+		keys := o.AccountKeys("carol")
+		assert.True(t, keys[2].Revoked)
+		assert.False(t, keys[0].Revoked)
+		sendFlow(WithSignerKeyIndex("carol", 2)).AssertFailure(t, "revoked")
+	})
+
+	t.Run("Should fail revoking key that does not exist", func(t *testing.T) {
+		err := o.RevokeAccountKeyE(context.Background(), "carol", 10)
+		assert.ErrorContains(t, err, "no key with index 10")
+	})
+}
@@ -0,0 +1,138 @@
+package overflow
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Balance changes
+//
+// The FungibleToken.Withdrawn and FungibleToken.Deposited events in a transaction are summed up into the change in balance for each address and vault type
+
+// the smallest unit of an UFix64, amounts are summed in this unit so that they are exact
+const ufix64Factor = 100_000_000
+
+// a type holding the change in balance for each address and vault type, the vault type is the full identifier like A.0ae53cb6e3f42a79.FlowToken.Vault
+type OverflowBalanceChanges map[string]map[string]float64
+
+// the change for the given address and a vault type that ends with the given suffix, 0 if there is no change
+func (bc OverflowBalanceChanges) Get(address string, vaultType string) float64 {
+	change := 0.0
+	for vault, amount := range bc[address] {
+		if strings.HasSuffix(vault, vaultType) {
+			change += amount
+		}
+	}
+	return change
+}
+
+// a human readable version of the changes sorted by address
+func (bc OverflowBalanceChanges) String() string {
+	lines := []string{}
+	for address, vaults := range bc {
+		for vault, amount := range vaults {
+			lines = append(lines, fmt.Sprintf("%s %s %+.8f", address, vault, amount))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// BalanceChanges sums up the amounts in all the FungibleToken events of the transaction for each address and vault type
+// If fee is not 0 the withdrawal of the fee from the payer and the deposit to the fee receipient is not included
+func (me OverflowEvents) BalanceChanges(fee float64, payer string) OverflowBalanceChanges {
+	units := map[string]map[string]int64{}
+	add := func(event OverflowEvent, field string, sign int64) {
+		address, ok := event.Fields[field].(string)
+		if !ok {
+			return
+		}
+		vaultType, _ := event.Fields["type"].(string)
+		amount, _ := event.Fields["amount"].(float64)
+		vaults, ok := units[address]
+		if !ok {
+			vaults = map[string]int64{}
+			units[address] = vaults
+		}
+		vaults[vaultType] += sign * int64(math.Round(amount*ufix64Factor))
+	}
+
+	for name, events := range me {
+		for _, event := range events {
+			if strings.HasSuffix(name, ".FungibleToken.Withdrawn") {
+				if fee != 0 && isFeeWithdrawal(event, fee, payer) {
+					continue
+				}
+				add(event, "from", -1)
+			}
+			if strings.HasSuffix(name, ".FungibleToken.Deposited") {
+				if fee != 0 && isFeeDeposit(event, fee) {
+					continue
+				}
+				add(event, "to", 1)
+			}
+		}
+	}
+
+	changes := OverflowBalanceChanges{}
+	for address, vaults := range units {
+		for vaultType, amount := range vaults {
+			if amount == 0 {
+				continue
+			}
+			if _, ok := changes[address]; !ok {
+				changes[address] = map[string]float64{}
+			}
+			changes[address][vaultType] = float64(amount) / ufix64Factor
+		}
+	}
+	return changes
+}
+
+// BalanceChanges returns the change in balance for each address and vault type in this transaction, including fees
+func (o OverflowResult) BalanceChanges() OverflowBalanceChanges {
+	return o.parsedEvents().BalanceChanges(0, "")
+}
+
+// BalanceChangesWithoutFees returns the change in balance for each address and vault type in this transaction, without the transaction fee
+func (o OverflowResult) BalanceChangesWithoutFees() OverflowBalanceChanges {
+	fee, _ := o.Fee["amount"].(float64)
+	payer := ""
+	if o.Transaction != nil {
+		payer = fmt.Sprintf("0x%s", o.Transaction.Payer.Hex())
+	}
+	return o.parsedEvents().BalanceChanges(fee, payer)
+}
+
+// the events are parsed again from the raw events since the events in the result can be filtered
+func (o OverflowResult) parsedEvents() OverflowEvents {
+	if o.overflow == nil || len(o.RawEvents) == 0 {
+		return o.Events
+	}
+	// parsing does not fail, the second value is the fee event that is not needed here
+	events, _ := o.overflow.ParseEvents(o.RawEvents)
+	return events
+}
+
+// Assert that the balance of a vault type, matched on suffix, for an account has changed with the given amount, fees are not included
+// The account can be a name from flow.json or an address
+func (o OverflowResult) AssertBalanceChange(t *testing.T, account string, vaultType string, amount float64) OverflowResult {
+	t.Helper()
+	address, err := o.accountAddress(account)
+	if err != nil {
+		assert.Fail(t, err.Error())
+		return o
+	}
+
+	changes := o.BalanceChangesWithoutFees()
+	if !assert.InDelta(t, amount, changes.Get(address, vaultType), 1.0/ufix64Factor, "balance change of %s for %s", vaultType, account) {
+		printOrLog(t, "=== Balance changes ===")
+		printOrLog(t, changes.String())
+	}
+	return o
+}
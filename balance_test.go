@@ -0,0 +1,65 @@
+package overflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceChanges(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	res := o.Tx("sendFlow", WithSigner("first"), WithArg("amount", 1.0), WithArg("to", "second"))
+	res.AssertSuccess(t).
+		AssertBalanceChange(t, "first", "FlowToken.Vault", -1.0).
+		AssertBalanceChange(t, "second", "FlowToken.Vault", 1.0).
+		AssertBalanceChange(t, o.Address("second"), "FlowToken.Vault", 1.0)
+
+	t.Run("Should include fees", func(t *testing.T) {
+		fee, ok := res.Fee["amount"].(float64)
+		require.True(t, ok)
+		require.NotZero(t, fee)
+
+		changes := res.BalanceChanges()
+		assert.InDelta(t, -1.0-fee, changes.Get(o.Address("first"), "FlowToken.Vault"), 0.000000001)
+		assert.InDelta(t, 1.0, changes.Get(o.Address("second"), "FlowToken.Vault"), 0.000000001)
+	})
+
+	t.Run("Should marshal the result to json", func(t *testing.T) {
+		data, err := json.Marshal(res)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"Name":"sendFlow"`)
+	})
+
+	t.Run("Should ignore fee events without an amount", func(t *testing.T) {
+		event := OverflowEvent{Fields: map[string]interface{}{"from": "0x01", "to": "0xe5a8b7f23e8b548f"}}
+		assert.False(t, isFeeWithdrawal(event, 0.1, "0x01"))
+		assert.False(t, isFeeDeposit(event, 0.1))
+	})
+
+	t.Run("Should sum up events", func(t *testing.T) {
+		events := OverflowEvents{
+			"A.ee82856bf20e2aa6.FungibleToken.Withdrawn": []OverflowEvent{
+				{Fields: map[string]interface{}{"type": "A.0ae53cb6e3f42a79.FlowToken.Vault", "amount": 0.1, "from": "0x01"}},
+				{Fields: map[string]interface{}{"type": "A.0ae53cb6e3f42a79.FlowToken.Vault", "amount": 0.2, "from": "0x01"}},
+				{Fields: map[string]interface{}{"type": "A.0ae53cb6e3f42a79.FlowToken.Vault", "amount": 0.001, "from": "0x01"}},
+				{Fields: map[string]interface{}{"type": "A.0ae53cb6e3f42a79.FlowToken.Vault", "amount": 5.0}},
+			},
+			"A.ee82856bf20e2aa6.FungibleToken.Deposited": []OverflowEvent{
+				{Fields: map[string]interface{}{"type": "A.0ae53cb6e3f42a79.FlowToken.Vault", "amount": 0.3, "to": "0x02"}},
+				{Fields: map[string]interface{}{"type": "A.0ae53cb6e3f42a79.FlowToken.Vault", "amount": 0.001, "to": "0xe5a8b7f23e8b548f"}},
+				{Fields: map[string]interface{}{"type": "A.01.Other.Vault", "amount": 1.0, "to": "0x01"}},
+			},
+		}
+
+		assert.Equal(t, OverflowBalanceChanges{
+			"0x01": {"A.0ae53cb6e3f42a79.FlowToken.Vault": -0.3, "A.01.Other.Vault": 1.0},
+			"0x02": {"A.0ae53cb6e3f42a79.FlowToken.Vault": 0.3},
+		}, events.BalanceChanges(0.001, "0x01"))
+
+		assert.Equal(t, -0.301, events.BalanceChanges(0, "").Get("0x01", "FlowToken.Vault"))
+	})
+}
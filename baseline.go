@@ -0,0 +1,241 @@
+package overflow
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// Computation baselines
+//
+// A baseline file records the computation and memory used by each transaction, keyed by name and arguments, so that tests fail when usage grows.
+// Like autogold the file is updated when tests are run with -update
+//
+//	func TestMain(m *testing.M) {
+//		baseline, _ = NewComputationBaseline("testdata/computation-baseline.json")
+//		code := m.Run()
+//		baseline.Close()
+//		os.Exit(code)
+//	}
+//
+//	o.Tx("mint_tokens", ...).AssertComputationBaseline(t, baseline)
+
+// the computation and memory used by an interaction
+type OverflowComputationUsage struct {
+	Computation int `json:"computation"`
+	Memory      int `json:"memory"`
+}
+
+// a type representing setting an option on a baseline
+type OverflowBaselineOption func(*OverflowComputationBaseline)
+
+// a baseline of the computation and memory used by transactions stored in a file
+type OverflowComputationBaseline struct {
+	// the json file the baseline is stored in
+	File string
+
+	// how many percent usage can grow before an assertion fails
+	Tolerance float64
+
+	// record the usage instead of asserting, if it is not set with WithBaselineUpdate the -update flag is read when usage is recorded
+	// so that the baseline can be created in TestMain before the flags are parsed
+	Update bool
+
+	updateSet bool
+	mutex     sync.Mutex
+	baseline  map[string]OverflowComputationUsage
+	current   map[string]OverflowComputationUsage
+}
+
+// allow usage to grow with the given percentage before failing, default is 0
+func WithBaselineTolerance(percentage float64) OverflowBaselineOption {
+	return func(b *OverflowComputationBaseline) {
+		b.Tolerance = percentage
+	}
+}
+
+// record the usage in the baseline instead of asserting against it
+func WithBaselineUpdate(update bool) OverflowBaselineOption {
+	return func(b *OverflowComputationBaseline) {
+		b.Update = update
+		b.updateSet = true
+	}
+}
+
+// the -update flag registered by autogold
+func updateFlag() bool {
+	update := flag.Lookup("update")
+	return update != nil && update.Value.String() == "true"
+}
+
+// if usage is recorded instead of asserted
+func (b *OverflowComputationBaseline) updating() bool {
+	if b.Update {
+		return true
+	}
+	return !b.updateSet && updateFlag()
+}
+
+// NewComputationBaseline reads the baseline from the given file, a missing file is an empty baseline
+func NewComputationBaseline(file string, opts ...OverflowBaselineOption) (*OverflowComputationBaseline, error) {
+	b := &OverflowComputationBaseline{
+		File:     file,
+		baseline: map[string]OverflowComputationUsage{},
+		current:  map[string]OverflowComputationUsage{},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &b.baseline)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse computation baseline %s", file)
+	}
+	return b, nil
+}
+
+// the key of a transaction in the baseline, its name and the arguments sorted by name
+func (o OverflowResult) baselineKey() string {
+	names := []string{}
+	for name := range o.Arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := []string{}
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("%s=%s", name, o.Arguments[name]))
+	}
+	return fmt.Sprintf("%s(%s)", o.Name, strings.Join(args, ", "))
+}
+
+// Assert that the transaction does not use more computation or memory than in the baseline plus the tolerance
+// When updating the usage is recorded instead
+func (o OverflowResult) AssertComputationBaseline(t *testing.T, baseline *OverflowComputationBaseline) OverflowResult {
+	t.Helper()
+	if o.Name == "" {
+		assert.Fail(t, "the transaction needs a name to be used in a baseline, use WithName for inline transactions")
+		return o
+	}
+
+	usage := OverflowComputationUsage{Computation: o.ComputationUsed}
+	if o.Meter != nil {
+		usage.Memory = o.Meter.MemoryEstimate
+	}
+	assert.NoError(t, baseline.record(o.baselineKey(), usage))
+	return o
+}
+
+// record the usage for the key and return an error if it has grown beyond the tolerance
+func (b *OverflowComputationBaseline) record(key string, usage OverflowComputationUsage) error {
+	b.mutex.Lock()
+	b.current[key] = usage
+	expected, ok := b.baseline[key]
+	b.mutex.Unlock()
+
+	if b.updating() {
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("no computation baseline for %s in %s, run the tests with -update to record it", key, b.File)
+	}
+
+	limit := func(value int) float64 {
+		return float64(value) * (1 + b.Tolerance/100)
+	}
+	failures := []string{}
+	if float64(usage.Computation) > limit(expected.Computation) {
+		failures = append(failures, fmt.Sprintf("computation for %s grew from %d to %d which is more than the tolerance of %.1f%%", key, expected.Computation, usage.Computation, b.Tolerance))
+	}
+	if float64(usage.Memory) > limit(expected.Memory) {
+		failures = append(failures, fmt.Sprintf("memory for %s grew from %d to %d which is more than the tolerance of %.1f%%", key, expected.Memory, usage.Memory, b.Tolerance))
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+func percentageChange(before int, after int) string {
+	if before == 0 {
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", 100*float64(after-before)/float64(before))
+}
+
+// Summary is a table of the interactions where the usage has changed from the baseline
+func (b *OverflowComputationBaseline) Summary() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	keys := []string{}
+	for key, usage := range b.current {
+		if b.baseline[key] != usage {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-60s %12s %12s %8s %12s %12s %8s\n", "interaction", "computation", "was", "change", "memory", "was", "change")
+	for _, key := range keys {
+		usage := b.current[key]
+		before := b.baseline[key]
+		fmt.Fprintf(&sb, "%-60s %12d %12d %8s %12d %12d %8s\n",
+			key,
+			usage.Computation, before.Computation, percentageChange(before.Computation, usage.Computation),
+			usage.Memory, before.Memory, percentageChange(before.Memory, usage.Memory),
+		)
+	}
+	return sb.String()
+}
+
+// Save writes the usage recorded in this run to the file, entries that were not used in this run are kept
+func (b *OverflowComputationBaseline) Save() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for key, usage := range b.current {
+		b.baseline[key] = usage
+	}
+	bytes, err := json.MarshalIndent(b.baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(b.File), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(b.File, append(bytes, '\n'), 0o644)
+}
+
+// Close prints the summary of changes and saves the baseline when updating
+func (b *OverflowComputationBaseline) Close() error {
+	summary := b.Summary()
+	if summary != "" {
+		fmt.Printf("Computation changes from baseline %s\n%s", b.File, summary)
+	}
+	if !b.updating() {
+		return nil
+	}
+	return b.Save()
+}
@@ -0,0 +1,83 @@
+package overflow
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputationBaseline(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	mint := func() OverflowResult {
+		return o.Tx("mint_tokens", WithSignerServiceAccount(), WithArg("recipient", "first"), WithArg("amount", 1.0)).AssertSuccess(t)
+	}
+
+	t.Run("Should record baseline when updating", func(t *testing.T) {
+		baseline, err := NewComputationBaseline(file, WithBaselineUpdate(true))
+		require.NoError(t, err)
+
+		res := mint()
+		res.AssertComputationBaseline(t, baseline)
+		assert.Contains(t, baseline.Summary(), "mint_tokens(amount=1.00000000, recipient=0x179b6b1cb6755e31)")
+		require.NoError(t, baseline.Close())
+
+		reread, err := NewComputationBaseline(file)
+		require.NoError(t, err)
+		assert.Equal(t, OverflowComputationUsage{Computation: res.ComputationUsed, Memory: res.Meter.MemoryEstimate}, reread.baseline[res.baselineKey()])
+		assert.NotZero(t, res.Meter.MemoryEstimate)
+	})
+
+	t.Run("Should pass when usage is the same", func(t *testing.T) {
+		baseline, err := NewComputationBaseline(file)
+		require.NoError(t, err)
+
+		mint().AssertComputationBaseline(t, baseline)
+		assert.Empty(t, baseline.Summary())
+	})
+
+	t.Run("Should fail when usage grows beyond tolerance", func(t *testing.T) {
+		baseline, err := NewComputationBaseline(file, WithBaselineTolerance(10))
+		require.NoError(t, err)
+		res := mint()
+		key := res.baselineKey()
+		baseline.baseline[key] = OverflowComputationUsage{Computation: res.ComputationUsed - 1, Memory: res.Meter.MemoryEstimate}
+
+		assert.NoError(t, baseline.record(key, OverflowComputationUsage{Computation: res.ComputationUsed, Memory: res.Meter.MemoryEstimate}))
+
+		baseline.Tolerance = 0
+		err = baseline.record(key, OverflowComputationUsage{Computation: res.ComputationUsed, Memory: res.Meter.MemoryEstimate})
+		assert.ErrorContains(t, err, "which is more than the tolerance of 0.0%")
+		assert.Contains(t, baseline.Summary(), "+")
+	})
+
+	t.Run("Should read the update flag when recording", func(t *testing.T) {
+		update := flag.Lookup("update")
+		require.NotNil(t, update)
+		previous := update.Value.String()
+		defer func() { require.NoError(t, flag.Set("update", previous)) }()
+
+		baseline, err := NewComputationBaseline(filepath.Join(t.TempDir(), "flag.json"))
+		require.NoError(t, err)
+		require.NoError(t, flag.Set("update", "true"))
+		assert.NoError(t, baseline.record("new", OverflowComputationUsage{Computation: 1}))
+
+		disabled, err := NewComputationBaseline(filepath.Join(t.TempDir(), "flag.json"), WithBaselineUpdate(false))
+		require.NoError(t, err)
+		assert.ErrorContains(t, disabled.record("new", OverflowComputationUsage{Computation: 1}), "no computation baseline for new")
+	})
+
+	t.Run("Should fail without baseline", func(t *testing.T) {
+		baseline, err := NewComputationBaseline(filepath.Join(t.TempDir(), "missing.json"))
+		require.NoError(t, err)
+
+		err = baseline.record("mint_tokens()", OverflowComputationUsage{Computation: 10})
+		assert.ErrorContains(t, err, "no computation baseline for mint_tokens()")
+		assert.Contains(t, baseline.Summary(), "new")
+	})
+}
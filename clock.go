@@ -0,0 +1,134 @@
+package overflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flowkit/v2/gateway"
+)
+
+// Time control
+//
+// On the embedded emulator the time of blocks can be controlled from tests. As soon as AdvanceTime or SetBlockTime is called the clock of the emulator
+// stops and all new blocks get the time that was set, until it is changed again.
+
+// overflowClock is a clock for the emulator that only moves when told to
+type overflowClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *overflowClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *overflowClock) set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now.UTC()
+}
+
+// the blockchain inside a flowkit emulator gateway, flowkit creates it from the emulator options and does not expose it
+// setup fails if a flowkit upgrade renames or retypes the field so that time control never breaks silently
+func gatewayBlockchain(gw *gateway.EmulatorGateway) (*emulator.Blockchain, error) {
+	field := reflect.ValueOf(gw).Elem().FieldByName("emulator")
+	if !field.IsValid() || field.Type() != reflect.TypeOf((*emulator.Blockchain)(nil)) {
+		return nil, fmt.Errorf("could not find the blockchain in the flowkit emulator gateway")
+	}
+	return *(**emulator.Blockchain)(unsafe.Pointer(field.UnsafeAddr())), nil
+}
+
+// the blockchain of the embedded emulator
+func (o *OverflowState) emulatorBlockchain() (*emulator.Blockchain, error) {
+	if o.emulator == nil {
+		return nil, fmt.Errorf("time can only be controlled on the embedded emulator")
+	}
+	return o.emulator, nil
+}
+
+// AdvanceTime moves the time of the chain forward by the given duration from the time of the latest block and commits a block with that time
+func (o *OverflowState) AdvanceTime(duration time.Duration) error {
+	if duration < 0 {
+		return fmt.Errorf("can not advance time by a negative duration %s", duration)
+	}
+	block, err := o.GetLatestBlock(context.Background())
+	if err != nil {
+		return err
+	}
+	return o.SetBlockTime(block.Timestamp.Add(duration))
+}
+
+// SetBlockTime sets the time of the chain and commits a block with that time, so it is visible both in scripts and transactions
+// The time can not be before the time of the latest block
+func (o *OverflowState) SetBlockTime(t time.Time) error {
+	blockchain, err := o.emulatorBlockchain()
+	if err != nil {
+		return err
+	}
+	block, err := o.GetLatestBlock(context.Background())
+	if err != nil {
+		return err
+	}
+	if t.Before(block.Timestamp) {
+		return fmt.Errorf("block time %s is before the time of the latest block %s", t.UTC(), block.Timestamp.UTC())
+	}
+
+	unlock := o.lockInteraction(true)
+	defer unlock()
+
+	if o.clock == nil {
+		o.clock = &overflowClock{}
+	}
+	o.clock.set(t)
+	// setting the clock again updates the time of the pending block
+	blockchain.SetClock(o.clock)
+	_, err = blockchain.CommitBlock()
+	return err
+}
+
+// CommitBlocks commits the given number of empty blocks
+func (o *OverflowState) CommitBlocks(n int) error {
+	blockchain, err := o.emulatorBlockchain()
+	if err != nil {
+		return err
+	}
+
+	unlock := o.lockInteraction(true)
+	defer unlock()
+
+	for i := 0; i < n; i++ {
+		_, err := blockchain.CommitBlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// use the time of the system again after the time has been controlled
+func (o *OverflowState) resetClock() error {
+	if o.emulator == nil {
+		return nil
+	}
+	blockchain, err := o.emulatorBlockchain()
+	if err != nil {
+		return err
+	}
+
+	unlock := o.lockInteraction(true)
+	defer unlock()
+
+	if o.clock == nil {
+		return nil
+	}
+	blockchain.SetClock(emulator.NewSystemClock())
+	o.clock = nil
+	return nil
+}
@@ -0,0 +1,84 @@
+package overflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeControl(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	blockTime := func(t *testing.T) time.Time {
+		t.Helper()
+		block, err := o.GetLatestBlock(context.Background())
+		require.NoError(t, err)
+		return block.Timestamp
+	}
+
+	timestampScript := `
+access(all) fun main(): UFix64 {
+	return getCurrentBlock().timestamp
+}`
+
+	t.Run("Should use the blockchain of the flowkit gateway", func(t *testing.T) {
+		blockchain, err := gatewayBlockchain(o.EmulatorGatway)
+		require.NoError(t, err)
+		require.NotNil(t, blockchain)
+		assert.Same(t, o.emulator, blockchain)
+	})
+
+	t.Run("Should set block time", func(t *testing.T) {
+		start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, o.SetBlockTime(start))
+		assert.Equal(t, start, blockTime(t).UTC())
+
+		var timestamp float64
+		require.NoError(t, o.Script(timestampScript).MarshalAs(&timestamp))
+		assert.Equal(t, float64(start.Unix()), timestamp)
+	})
+
+	t.Run("Should advance time", func(t *testing.T) {
+		start := blockTime(t)
+		require.NoError(t, o.AdvanceTime(24*time.Hour))
+		assert.Equal(t, start.Add(24*time.Hour), blockTime(t))
+
+		res := o.Tx(`
+transaction {
+	prepare(signer: &Account) {
+		log(getCurrentBlock().timestamp)
+	}
+}`, WithSignerServiceAccount())
+		res.AssertSuccess(t)
+		assert.Equal(t, start.Add(24*time.Hour), blockTime(t))
+	})
+
+	t.Run("Should commit blocks", func(t *testing.T) {
+		block, err := o.GetLatestBlock(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, o.CommitBlocks(3))
+
+		latest, err := o.GetLatestBlock(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, block.Height+3, latest.Height)
+		assert.Equal(t, block.Timestamp, latest.Timestamp)
+	})
+
+	t.Run("Should not go back in time", func(t *testing.T) {
+		err := o.SetBlockTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.ErrorContains(t, err, "is before the time of the latest block")
+
+		err = o.AdvanceTime(-time.Hour)
+		assert.ErrorContains(t, err, "negative duration")
+	})
+
+	t.Run("Should use system time after reset", func(t *testing.T) {
+		require.NoError(t, o.resetClock())
+		require.NoError(t, o.CommitBlocks(1))
+		assert.WithinDuration(t, time.Now(), blockTime(t), time.Minute)
+	})
+}
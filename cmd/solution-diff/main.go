@@ -0,0 +1,42 @@
+// Command solution-diff prints what changed from a published solution json to the solution of the project in the current folder
+// and exits with status 1 if there are breaking changes, so it can stop a release in CI
+//
+//	go run github.com/bjartek/overflow/v2/cmd/solution-diff npm/solution.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bjartek/overflow/v2"
+)
+
+func main() {
+	network := flag.String("network", "embedded", "the network overflow is started on to parse the project")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-network name] <solution.json>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	o := overflow.Overflow(overflow.WithNetwork(*network), overflow.WithLogNone())
+	if o.Error != nil {
+		fmt.Fprintln(os.Stderr, o.Error)
+		os.Exit(2)
+	}
+
+	diff, err := o.DiffSolutionFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Print(diff)
+	if len(diff.Breaking()) > 0 {
+		os.Exit(1)
+	}
+}
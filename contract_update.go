@@ -0,0 +1,150 @@
+package overflow
+
+import (
+	"context"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/stdlib"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/pkg/errors"
+)
+
+// Contract updates
+//
+// Before upgrading contracts on a network you can check if the local version of the contracts in the deployment can be updated using the same validator as the network uses
+
+// the status of a contract when comparing the local source with the code on chain
+type OverflowContractUpdateStatus string
+
+const (
+	// the code on chain is the same as the local source
+	ContractUnchanged OverflowContractUpdateStatus = "unchanged"
+	// the local source differs from the code on chain and can be updated
+	ContractUpdatable OverflowContractUpdateStatus = "updatable"
+	// the local source differs from the code on chain and the network would reject the update
+	ContractRejected OverflowContractUpdateStatus = "rejected"
+	// the contract is not deployed on chain yet
+	ContractNotDeployed OverflowContractUpdateStatus = "not deployed"
+)
+
+// the result of checking if a single contract can be updated
+type OverflowContractUpdate struct {
+	Account  string
+	Address  flow.Address
+	Contract string
+	Status   OverflowContractUpdateStatus
+	// the reasons the update would be rejected
+	Errors []string
+}
+
+// CheckContractUpdates checks all the contracts in the deployment for the current network against the code on chain
+// The result is keyed on account name and then contract name
+func (o *OverflowState) CheckContractUpdates(ctx context.Context) (map[string]map[string]*OverflowContractUpdate, error) {
+	deploymentContracts, err := o.State.DeploymentContractsByNetwork(o.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	localContracts, err := o.contracts(o.Network)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not resolve contracts for network %s", o.Network.Name)
+	}
+
+	onChainAccounts := map[flow.Address]*flow.Account{}
+	result := map[string]map[string]*OverflowContractUpdate{}
+	for _, contract := range deploymentContracts {
+		account, ok := onChainAccounts[contract.AccountAddress]
+		if !ok {
+			account, err = o.Flowkit.GetAccount(ctx, contract.AccountAddress)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not get account %s", contract.AccountName)
+			}
+			onChainAccounts[contract.AccountAddress] = account
+		}
+
+		accountName := o.logicalAccountName(contract.AccountName)
+		if result[accountName] == nil {
+			result[accountName] = map[string]*OverflowContractUpdate{}
+		}
+		result[accountName][contract.Name] = checkContractUpdate(account, contract.Name, localContracts[contract.Name])
+		result[accountName][contract.Name].Account = accountName
+	}
+	return result, nil
+}
+
+func checkContractUpdate(account *flow.Account, contractName string, code string) *OverflowContractUpdate {
+	update := &OverflowContractUpdate{
+		Address:  account.Address,
+		Contract: contractName,
+	}
+
+	oldCode, ok := account.Contracts[contractName]
+	if !ok {
+		update.Status = ContractNotDeployed
+		return update
+	}
+
+	if strings.TrimSpace(string(oldCode)) == strings.TrimSpace(code) {
+		update.Status = ContractUnchanged
+		return update
+	}
+
+	err := validateContractUpdate(account, contractName, oldCode, []byte(code))
+	if err == nil {
+		update.Status = ContractUpdatable
+		return update
+	}
+
+	update.Status = ContractRejected
+	var updateErr *stdlib.ContractUpdateError
+	if errors.As(err, &updateErr) {
+		for _, childErr := range updateErr.ChildErrors() {
+			update.Errors = append(update.Errors, childErr.Error())
+		}
+	} else {
+		update.Errors = append(update.Errors, err.Error())
+	}
+	return update
+}
+
+func validateContractUpdate(account *flow.Account, contractName string, oldCode []byte, newCode []byte) error {
+	oldProgram, err := parser.ParseProgram(nil, oldCode, parser.Config{IgnoreLeadingIdentifierEnabled: true})
+	if err != nil {
+		return errors.Wrap(err, "could not parse code on chain")
+	}
+
+	newProgram, err := parser.ParseProgram(nil, newCode, parser.Config{})
+	if err != nil {
+		return errors.Wrap(err, "could not parse local code")
+	}
+
+	location := common.AddressLocation{
+		Address: common.Address(account.Address),
+		Name:    contractName,
+	}
+
+	return stdlib.NewContractUpdateValidator(
+		location,
+		contractName,
+		accountContractNames{account: account},
+		oldProgram,
+		newProgram,
+	).Validate()
+}
+
+// provides the names of the contracts on chain to the update validator
+type accountContractNames struct {
+	account *flow.Account
+}
+
+func (a accountContractNames) GetAccountContractNames(_ common.Address) ([]string, error) {
+	names := []string{}
+	for name := range a.account.Contracts {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+var _ stdlib.AccountContractNamesProvider = accountContractNames{}
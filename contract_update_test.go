@@ -0,0 +1,81 @@
+package overflow
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hexops/autogold"
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updates the Debug contract on the emulator with the given code
+func updateDebugContract(t *testing.T, o *OverflowState, code string) {
+	t.Helper()
+	o.Tx(`
+transaction(code: String) {
+	prepare(signer: auth(UpdateContract) &Account) {
+		signer.contracts.update(name: "Debug", code: code.utf8)
+	}
+}`,
+		WithSigner("account"),
+		WithArg("code", cadence.String(strings.ReplaceAll(code, `"NonFungibleToken.cdc"`, o.Address("account")))),
+	).AssertSuccess(t)
+}
+
+func TestCheckContractUpdate(t *testing.T) {
+	debug, err := os.ReadFile("contracts/Debug.cdc")
+	require.NoError(t, err)
+
+	statuses := func(t *testing.T, o *OverflowState) map[string]map[string]OverflowContractUpdateStatus {
+		t.Helper()
+		updates, err := o.CheckContractUpdates(context.Background())
+		require.NoError(t, err)
+		result := map[string]map[string]OverflowContractUpdateStatus{}
+		for account, contracts := range updates {
+			result[account] = map[string]OverflowContractUpdateStatus{}
+			for name, update := range contracts {
+				result[account][name] = update.Status
+			}
+		}
+		return result
+	}
+
+	t.Run("Should return the updatable contracts", func(t *testing.T) {
+		o, err := OverflowTesting()
+		require.NoError(t, err)
+		autogold.Equal(t, statuses(t, o))
+	})
+
+	t.Run("Should return the updatable contracts (updatable)", func(t *testing.T) {
+		o, err := OverflowTesting()
+		require.NoError(t, err)
+
+		// the code on chain has an extra function so the local source is a valid update
+		updateDebugContract(t, o, strings.Replace(string(debug), "access(all) fun id(", "access(all) fun id2() {}\n\n    access(all) fun id(", 1))
+		autogold.Equal(t, statuses(t, o))
+	})
+
+	t.Run("Should report why update is rejected", func(t *testing.T) {
+		o, err := OverflowTesting()
+		require.NoError(t, err)
+
+		// the code on chain is missing a field so the local source would add a field
+		withoutField := strings.NewReplacer(
+			"access(all) let bar: Address\n", "",
+			"init(bar: Address) {\n            self.bar=bar\n", "init(bar: Address) {\n",
+		).Replace(string(debug))
+		updateDebugContract(t, o, withoutField)
+		updates, err := o.CheckContractUpdates(context.Background())
+		require.NoError(t, err)
+
+		update := updates["account"]["Debug"]
+		assert.Equal(t, ContractRejected, update.Status)
+		assert.Equal(t, o.Address("account"), "0x"+update.Address.String())
+		require.Len(t, update.Errors, 1)
+		assert.Contains(t, update.Errors[0], "Foo2")
+	})
+}
@@ -0,0 +1,469 @@
+package overflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/pkg/errors"
+)
+
+// Coverage
+//
+// When overflow is started WithCoverageReport the lines run in cadence are recorded, the report can be written as json, LCOV and HTML and checked against thresholds
+
+// a type representing setting an option when writing a coverage report
+type OverflowCoverageOption func(*OverflowCoverageBuilder)
+
+// a type representing how a coverage report is written and checked
+type OverflowCoverageBuilder struct {
+	// the file to write the raw json report to, empty to skip
+	JSONFile string
+
+	// the file to write the LCOV report to, empty to skip
+	LCOVFile string
+
+	// the directory to write the HTML report to, empty to skip
+	HTMLDir string
+
+	// json reports from other test packages that are merged in before writing
+	MergeFiles []string
+
+	// only locations with an id matching one of these are kept, all if empty
+	Include []*regexp.Regexp
+
+	// locations with an id matching one of these are removed
+	Exclude []*regexp.Regexp
+
+	// the minimum percentage of covered statements for each location
+	Threshold float64
+
+	// the minimum percentage of covered statements for a given location id, like A.f8d6e0586b0a20c7.Debug, overrides Threshold
+	ContractThresholds map[string]float64
+
+	Error error
+}
+
+// write the json report to the given file, default coverage-report.json
+func WithCoverageJSON(file string) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.JSONFile = file
+	}
+}
+
+// write a LCOV report to the given file
+func WithCoverageLCOV(file string) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.LCOVFile = file
+	}
+}
+
+// write a HTML report with an index and a page for each location to the given directory
+func WithCoverageHTML(dir string) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.HTMLDir = dir
+	}
+}
+
+// merge in json reports written by other test packages
+func WithCoverageMerge(files ...string) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.MergeFiles = append(ocb.MergeFiles, files...)
+	}
+}
+
+// only keep locations where the id, like A.f8d6e0586b0a20c7.Debug, match one of the given regular expressions
+func WithCoverageInclude(patterns ...string) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.Include = append(ocb.Include, ocb.compile(patterns)...)
+	}
+}
+
+// remove locations where the id match one of the given regular expressions
+func WithCoverageExclude(patterns ...string) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.Exclude = append(ocb.Exclude, ocb.compile(patterns)...)
+	}
+}
+
+// fail if any location has a lower percentage of covered statements
+func WithCoverageThreshold(percentage float64) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.Threshold = percentage
+	}
+}
+
+// fail if the location with the given id, like A.f8d6e0586b0a20c7.Debug, has a lower percentage of covered statements
+// The id includes the address so contracts with the same name on different accounts get their own threshold
+func WithContractCoverageThreshold(location string, percentage float64) OverflowCoverageOption {
+	return func(ocb *OverflowCoverageBuilder) {
+		ocb.ContractThresholds[location] = percentage
+	}
+}
+
+func (ocb *OverflowCoverageBuilder) compile(patterns []string) []*regexp.Regexp {
+	result := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			ocb.Error = errors.Wrapf(err, "invalid coverage filter %s", pattern)
+			continue
+		}
+		result = append(result, re)
+	}
+	return result
+}
+
+func (ocb *OverflowCoverageBuilder) includes(location common.Location) bool {
+	id := location.ID()
+	for _, re := range ocb.Exclude {
+		if re.MatchString(id) {
+			return false
+		}
+	}
+	if len(ocb.Include) == 0 {
+		return true
+	}
+	for _, re := range ocb.Include {
+		if re.MatchString(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadCoverageReports reads json coverage reports from files and merges them
+func ReadCoverageReports(files ...string) (*runtime.CoverageReport, error) {
+	result := runtime.NewCoverageReport()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read coverage report %s", file)
+		}
+		report := runtime.NewCoverageReport()
+		err = json.Unmarshal(content, report)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse coverage report %s", file)
+		}
+		MergeCoverageReport(result, report)
+	}
+	return result, nil
+}
+
+// MergeCoverageReport adds the hits from other into report, line hits for the same location are summed up
+func MergeCoverageReport(report *runtime.CoverageReport, other *runtime.CoverageReport) {
+	for location, coverage := range other.Coverage {
+		existing, ok := report.Coverage[location]
+		if !ok {
+			lineHits := map[int]int{}
+			for line, hits := range coverage.LineHits {
+				lineHits[line] = hits
+			}
+			report.Coverage[location] = &runtime.LocationCoverage{LineHits: lineHits, Statements: coverage.Statements}
+			report.Locations[location] = struct{}{}
+			continue
+		}
+		for line, hits := range coverage.LineHits {
+			existing.LineHits[line] += hits
+		}
+		if coverage.Statements > existing.Statements {
+			existing.Statements = coverage.Statements
+		}
+	}
+	for location := range other.ExcludedLocations {
+		report.ExcludedLocations[location] = struct{}{}
+	}
+}
+
+// the percentage of covered statements for a location
+func coveragePercentage(coverage *runtime.LocationCoverage) float64 {
+	if coverage.Statements == 0 {
+		return 100
+	}
+	covered := coverage.CoveredLines()
+	if covered > coverage.Statements {
+		covered = coverage.Statements
+	}
+	return 100 * float64(covered) / float64(coverage.Statements)
+}
+
+// the name used for a location in reports, the contract name for contracts
+func coverageName(location common.Location) string {
+	if addressLocation, ok := location.(common.AddressLocation); ok {
+		return addressLocation.Name
+	}
+	return location.ID()
+}
+
+// CheckCoverage returns an error listing all locations in the report that are below the thresholds
+func CheckCoverage(report *runtime.CoverageReport, opts ...OverflowCoverageOption) error {
+	ocb := newOverflowCoverageBuilder(opts)
+	if ocb.Error != nil {
+		return ocb.Error
+	}
+	return ocb.check(ocb.filter(report))
+}
+
+func newOverflowCoverageBuilder(opts []OverflowCoverageOption) *OverflowCoverageBuilder {
+	ocb := &OverflowCoverageBuilder{
+		JSONFile:           "coverage-report.json",
+		ContractThresholds: map[string]float64{},
+	}
+	for _, opt := range opts {
+		opt(ocb)
+	}
+	return ocb
+}
+
+func (ocb *OverflowCoverageBuilder) filter(report *runtime.CoverageReport) *runtime.CoverageReport {
+	result := runtime.NewCoverageReport()
+	for location, coverage := range report.Coverage {
+		if ocb.includes(location) {
+			result.Coverage[location] = coverage
+			result.Locations[location] = struct{}{}
+		}
+	}
+	for location := range report.ExcludedLocations {
+		result.ExcludedLocations[location] = struct{}{}
+	}
+	return result
+}
+
+func (ocb *OverflowCoverageBuilder) check(report *runtime.CoverageReport) error {
+	failures := []string{}
+	for _, location := range sortedCoverageLocations(report) {
+		id := location.ID()
+		threshold, ok := ocb.ContractThresholds[id]
+		if !ok {
+			threshold = ocb.Threshold
+		}
+		percentage := coveragePercentage(report.Coverage[location])
+		if percentage < threshold {
+			failures = append(failures, fmt.Sprintf("coverage for %s is %.1f%% which is below the threshold of %.1f%%", id, percentage, threshold))
+		}
+	}
+	for id := range ocb.ContractThresholds {
+		found := false
+		for location := range report.Coverage {
+			if location.ID() == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			failures = append(failures, fmt.Sprintf("no coverage for %s that has a threshold", id))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	sort.Strings(failures)
+	return errors.New(strings.Join(failures, "\n"))
+}
+
+func sortedCoverageLocations(report *runtime.CoverageReport) []common.Location {
+	locations := []common.Location{}
+	for location := range report.Coverage {
+		locations = append(locations, location)
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].ID() < locations[j].ID()
+	})
+	return locations
+}
+
+// WriteCoverageReport writes the coverage report of this overflow in the configured formats and checks thresholds
+// The source of contracts used in the HTML report is fetched from the network
+func (o *OverflowState) WriteCoverageReport(opts ...OverflowCoverageOption) error {
+	if o.CoverageReport == nil {
+		return fmt.Errorf("coverage is not enabled, start overflow WithCoverageReport")
+	}
+	return o.writeCoverageReport(o.CoverageReport, opts)
+}
+
+func (o *OverflowState) writeCoverageReport(coverage *runtime.CoverageReport, opts []OverflowCoverageOption) error {
+	ocb := newOverflowCoverageBuilder(opts)
+	if ocb.Error != nil {
+		return ocb.Error
+	}
+
+	report := runtime.NewCoverageReport()
+	MergeCoverageReport(report, coverage)
+	if len(ocb.MergeFiles) > 0 {
+		others, err := ReadCoverageReports(ocb.MergeFiles...)
+		if err != nil {
+			return err
+		}
+		MergeCoverageReport(report, others)
+	}
+	report = ocb.filter(report)
+
+	if ocb.JSONFile != "" {
+		bytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(ocb.JSONFile, bytes, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ocb.LCOVFile != "" {
+		bytes, err := report.MarshalLCOV()
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(ocb.LCOVFile, bytes, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ocb.HTMLDir != "" {
+		err := writeCoverageHTML(ocb.HTMLDir, report, o.coverageSources(report))
+		if err != nil {
+			return errors.Wrap(err, "could not write html coverage report")
+		}
+	}
+
+	return ocb.check(report)
+}
+
+// the code of the contracts in the report from the network
+func (o *OverflowState) coverageSources(report *runtime.CoverageReport) map[common.Location]string {
+	sources := map[common.Location]string{}
+	accounts := map[common.Address]*flow.Account{}
+	for location := range report.Coverage {
+		addressLocation, ok := location.(common.AddressLocation)
+		if !ok {
+			continue
+		}
+		account, ok := accounts[addressLocation.Address]
+		if !ok {
+			var err error
+			account, err = o.Flowkit.GetAccount(context.Background(), flow.Address(addressLocation.Address))
+			if err != nil {
+				continue
+			}
+			accounts[addressLocation.Address] = account
+		}
+		if code, ok := account.Contracts[addressLocation.Name]; ok {
+			sources[location] = string(code)
+		}
+	}
+	return sources
+}
+
+type coverageHTMLLine struct {
+	Number int
+	Code   string
+	Class  string
+	Hits   int
+}
+
+type coverageHTMLLocation struct {
+	Name       string
+	ID         string
+	File       string
+	Statements int
+	Covered    int
+	Percentage string
+	Lines      []coverageHTMLLine
+}
+
+var coverageIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Cadence coverage</title>
+<style>body{font-family:sans-serif} td,th{padding:2px 12px;text-align:left}</style>
+</head>
+<body>
+<h1>Cadence coverage</h1>
+<table>
+<tr><th>Name</th><th>Location</th><th>Statements</th><th>Covered</th><th>Percentage</th></tr>
+{{range .}}<tr><td><a href="{{.File}}">{{.Name}}</a></td><td>{{.ID}}</td><td>{{.Statements}}</td><td>{{.Covered}}</td><td>{{.Percentage}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var coverageLocationTemplate = template.Must(template.New("location").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Name}} coverage</title>
+<style>body{font-family:sans-serif} pre{margin:0} .hit{background:#dfd} .miss{background:#fdd} .line{color:#888;padding-right:12px;text-align:right} .hits{color:#888;padding-right:12px;text-align:right}</style>
+</head>
+<body>
+<h1>{{.Name}} {{.Percentage}}</h1>
+<p><a href="index.html">index</a> {{.ID}} {{.Covered}}/{{.Statements}} statements covered</p>
+<table cellspacing="0">
+{{range .Lines}}<tr class="{{.Class}}"><td class="line">{{.Number}}</td><td class="hits">{{if .Class}}{{.Hits}}{{end}}</td><td><pre>{{.Code}}</pre></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var coverageFileNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+func writeCoverageHTML(dir string, report *runtime.CoverageReport, sources map[common.Location]string) error {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	locations := []coverageHTMLLocation{}
+	for _, location := range sortedCoverageLocations(report) {
+		coverage := report.Coverage[location]
+		page := coverageHTMLLocation{
+			Name:       coverageName(location),
+			ID:         location.ID(),
+			File:       coverageFileNameReplacer.ReplaceAllString(location.ID(), "_") + ".html",
+			Statements: coverage.Statements,
+			Covered:    coverage.CoveredLines(),
+			Percentage: fmt.Sprintf("%.1f%%", coveragePercentage(coverage)),
+		}
+
+		source, ok := sources[location]
+		if ok {
+			for i, code := range strings.Split(source, "\n") {
+				page.Lines = append(page.Lines, coverageHTMLLine{Number: i + 1, Code: code})
+			}
+		}
+		for line, hits := range coverage.LineHits {
+			for len(page.Lines) < line {
+				page.Lines = append(page.Lines, coverageHTMLLine{Number: len(page.Lines) + 1})
+			}
+			page.Lines[line-1].Hits = hits
+			page.Lines[line-1].Class = "miss"
+			if hits > 0 {
+				page.Lines[line-1].Class = "hit"
+			}
+		}
+
+		file, err := os.Create(filepath.Join(dir, page.File))
+		if err != nil {
+			return err
+		}
+		err = coverageLocationTemplate.Execute(file, page)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		locations = append(locations, page)
+	}
+
+	file, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return coverageIndexTemplate.Execute(file, locations)
+}
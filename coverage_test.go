@@ -0,0 +1,105 @@
+package overflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageReport(t *testing.T) {
+	o, err := OverflowTesting(WithCoverageReport())
+	require.NoError(t, err)
+	o.Tx("mint_tokens", WithSignerServiceAccount(), WithArg("recipient", "first"), WithArg("amount", 1.0)).AssertSuccess(t)
+
+	t.Run("Should write json, lcov and html", func(t *testing.T) {
+		dir := t.TempDir()
+		err := o.WriteCoverageReport(
+			WithCoverageJSON(filepath.Join(dir, "coverage.json")),
+			WithCoverageLCOV(filepath.Join(dir, "lcov.info")),
+			WithCoverageHTML(filepath.Join(dir, "html")),
+			WithCoverageInclude(`\.FlowToken$`),
+		)
+		require.NoError(t, err)
+
+		report, err := ReadCoverageReports(filepath.Join(dir, "coverage.json"))
+		require.NoError(t, err)
+		require.Len(t, report.Coverage, 1)
+		for location := range report.Coverage {
+			assert.Equal(t, "FlowToken", coverageName(location))
+		}
+
+		lcov, err := os.ReadFile(filepath.Join(dir, "lcov.info"))
+		require.NoError(t, err)
+		assert.Contains(t, string(lcov), "SF:A.0ae53cb6e3f42a79.FlowToken")
+
+		index, err := os.ReadFile(filepath.Join(dir, "html", "index.html"))
+		require.NoError(t, err)
+		assert.Contains(t, string(index), `href="A.0ae53cb6e3f42a79.FlowToken.html"`)
+
+		page, err := os.ReadFile(filepath.Join(dir, "html", "A.0ae53cb6e3f42a79.FlowToken.html"))
+		require.NoError(t, err)
+		assert.Contains(t, string(page), `class="hit"`)
+		assert.Contains(t, string(page), "access(all) contract FlowToken")
+	})
+
+	t.Run("Should exclude locations", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "coverage.json")
+		err := o.WriteCoverageReport(WithCoverageJSON(file), WithCoverageExclude(`^t\.`, `FlowToken`))
+		require.NoError(t, err)
+
+		report, err := ReadCoverageReports(file)
+		require.NoError(t, err)
+		assert.NotEmpty(t, report.Coverage)
+		for location := range report.Coverage {
+			assert.NotContains(t, location.ID(), "FlowToken")
+			assert.False(t, strings.HasPrefix(location.ID(), "t."))
+		}
+	})
+
+	t.Run("Should fail below threshold", func(t *testing.T) {
+		err := o.WriteCoverageReport(WithCoverageJSON(""), WithCoverageInclude(`FlowToken`), WithCoverageThreshold(99))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "coverage for A.0ae53cb6e3f42a79.FlowToken is")
+		assert.Contains(t, err.Error(), "below the threshold of 99.0%")
+	})
+
+	t.Run("Should use contract threshold", func(t *testing.T) {
+		err := o.WriteCoverageReport(WithCoverageJSON(""), WithCoverageInclude(`FlowToken`), WithCoverageThreshold(99), WithContractCoverageThreshold("A.0ae53cb6e3f42a79.FlowToken", 1))
+		assert.NoError(t, err)
+
+		err = o.WriteCoverageReport(WithCoverageJSON(""), WithCoverageInclude(`FlowToken`), WithContractCoverageThreshold("A.f8d6e0586b0a20c7.FlowToken", 1))
+		assert.ErrorContains(t, err, "no coverage for A.f8d6e0586b0a20c7.FlowToken that has a threshold")
+
+		err = o.WriteCoverageReport(WithCoverageJSON(""), WithContractCoverageThreshold("Missing", 1))
+		assert.ErrorContains(t, err, "no coverage for Missing that has a threshold")
+	})
+
+	t.Run("Should fail on invalid filter", func(t *testing.T) {
+		err := o.WriteCoverageReport(WithCoverageJSON(""), WithCoverageInclude(`(`))
+		assert.ErrorContains(t, err, "invalid coverage filter (")
+	})
+}
+
+func TestMergeCoverageReport(t *testing.T) {
+	location := common.StringLocation("test")
+
+	report := runtime.NewCoverageReport()
+	report.Coverage[location] = &runtime.LocationCoverage{LineHits: map[int]int{1: 1, 2: 0}, Statements: 2}
+	other := runtime.NewCoverageReport()
+	other.Coverage[location] = &runtime.LocationCoverage{LineHits: map[int]int{1: 2, 2: 3}, Statements: 2}
+	other.Coverage[common.StringLocation("other")] = &runtime.LocationCoverage{LineHits: map[int]int{1: 0}, Statements: 1}
+
+	MergeCoverageReport(report, other)
+	assert.Equal(t, map[int]int{1: 3, 2: 3}, report.Coverage[location].LineHits)
+	assert.Len(t, report.Coverage, 2)
+	assert.Equal(t, 100.0, coveragePercentage(report.Coverage[location]))
+
+	err := CheckCoverage(report, WithCoverageThreshold(50))
+	assert.ErrorContains(t, err, "coverage for S.other is 0.0% which is below the threshold of 50.0%")
+}
@@ -0,0 +1,229 @@
+package overflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Deployment plan
+//
+// PlanDeployment shows what InitializeContracts would do on the current network without sending anything, ApplyPlan executes the plan
+
+// the number of bytes of storage one flow token buys on chain
+const storageBytesPerFlow = 100_000_000
+
+// what will happen to a contract when a deployment plan is applied
+type OverflowDeploymentAction string
+
+const (
+	// the contract does not exist on the account and will be added
+	DeploymentCreate OverflowDeploymentAction = "create"
+	// the contract exists on the account with different code and will be updated
+	DeploymentUpdate OverflowDeploymentAction = "update"
+	// the contract exists on the account with the same code and will be skipped
+	DeploymentSkip OverflowDeploymentAction = "skip"
+)
+
+// a single contract in a deployment plan
+type OverflowPlannedContract struct {
+	Contract string
+	Account  string
+	Address  flow.Address
+	Action   OverflowDeploymentAction
+
+	// the local code with imports resolved for the network
+	Code string
+
+	// the code on chain when the plan was made, empty on create
+	OnChainCode string
+
+	// unified diff from the code on chain to the local code, empty on skip
+	Diff string
+
+	// the arguments sent to the init function of the contract on create
+	Arguments []cadence.Value
+
+	// the change in bytes of contract code stored on the account
+	StorageDelta int
+
+	// the unresolved source and its location used when sending the transaction
+	source   []byte
+	location string
+}
+
+// the flow needed to pay for storing the extra code
+func (c OverflowPlannedContract) StorageCost() float64 {
+	if c.StorageDelta <= 0 {
+		return 0
+	}
+	return float64(c.StorageDelta) / storageBytesPerFlow
+}
+
+// a plan for deploying all contracts in the deployment block of a network in dependency order
+type OverflowDeploymentPlan struct {
+	Network   string
+	Contracts []*OverflowPlannedContract
+}
+
+// the contracts in the plan that will change something on chain
+func (p OverflowDeploymentPlan) Changes() []*OverflowPlannedContract {
+	changes := []*OverflowPlannedContract{}
+	for _, c := range p.Contracts {
+		if c.Action != DeploymentSkip {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// the flow needed to pay for storing all the extra code in the plan
+func (p OverflowDeploymentPlan) StorageCost() float64 {
+	cost := 0.0
+	for _, c := range p.Contracts {
+		cost += c.StorageCost()
+	}
+	return cost
+}
+
+// a human readable version of the plan with diffs
+func (p OverflowDeploymentPlan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Deployment plan for network %s\n", p.Network)
+	for _, c := range p.Contracts {
+		fmt.Fprintf(&sb, "%-6s %s -> %s (0x%s)", c.Action, c.Contract, c.Account, c.Address.Hex())
+		if len(c.Arguments) > 0 {
+			fmt.Fprintf(&sb, " args=%v", c.Arguments)
+		}
+		if c.StorageDelta != 0 {
+			fmt.Fprintf(&sb, " storage=%+d bytes", c.StorageDelta)
+		}
+		sb.WriteString("\n")
+		if c.Action == DeploymentUpdate {
+			sb.WriteString(c.Diff)
+		}
+	}
+	fmt.Fprintf(&sb, "Storage cost %.8f FLOW\n", p.StorageCost())
+	return sb.String()
+}
+
+// PlanDeployment figures out what would happen if the contracts in the deployment block for the current network are deployed
+// Nothing is sent to the network
+func (o *OverflowState) PlanDeployment(ctx context.Context) (*OverflowDeploymentPlan, error) {
+	sorted, err := o.sortedDeploymentContracts(o.Network)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &OverflowDeploymentPlan{Network: o.Network.Name}
+	onChainAccounts := map[flow.Address]*flow.Account{}
+	for _, contract := range sorted {
+		account, ok := onChainAccounts[contract.AccountAddress]
+		if !ok {
+			account, err = o.Flowkit.GetAccount(ctx, contract.AccountAddress)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not get account %s", contract.AccountName)
+			}
+			onChainAccounts[contract.AccountAddress] = account
+		}
+
+		code, err := o.Parse(contract.Location(), contract.Code(), o.Network)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not resolve imports for contract %s", contract.Name)
+		}
+
+		planned := &OverflowPlannedContract{
+			Contract:  contract.Name,
+			Account:   o.logicalAccountName(contract.AccountName),
+			Address:   contract.AccountAddress,
+			Code:      code,
+			Arguments: contract.Args,
+			source:    contract.Code(),
+			location:  contract.Location(),
+		}
+
+		onChainCode, exists := account.Contracts[contract.Name]
+		planned.OnChainCode = string(onChainCode)
+		planned.StorageDelta = len(code) - len(onChainCode)
+		switch {
+		case !exists:
+			planned.Action = DeploymentCreate
+		case strings.TrimSpace(planned.OnChainCode) == code:
+			planned.Action = DeploymentSkip
+			planned.StorageDelta = 0
+		default:
+			planned.Action = DeploymentUpdate
+			planned.Arguments = nil
+			planned.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(planned.OnChainCode),
+				B:        difflib.SplitLines(code),
+				FromFile: fmt.Sprintf("0x%s.%s", contract.AccountAddress.Hex(), contract.Name),
+				ToFile:   contract.Location(),
+				Context:  3,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		plan.Contracts = append(plan.Contracts, planned)
+	}
+	return plan, nil
+}
+
+// ApplyPlan deploys the contracts in the plan in order
+// If the code on chain has changed since the plan was made nothing is sent and an error is returned
+func (o *OverflowState) ApplyPlan(ctx context.Context, plan *OverflowDeploymentPlan) error {
+	if plan.Network != o.Network.Name {
+		return fmt.Errorf("plan is for network %s but overflow is running on %s", plan.Network, o.Network.Name)
+	}
+
+	for _, c := range plan.Changes() {
+		account, err := o.Flowkit.GetAccount(ctx, c.Address)
+		if err != nil {
+			return errors.Wrapf(err, "could not get account %s", c.Account)
+		}
+		if !bytes.Equal(account.Contracts[c.Contract], []byte(c.OnChainCode)) {
+			return fmt.Errorf("contract %s on account %s has changed since the plan was made", c.Contract, c.Account)
+		}
+	}
+
+	names := []string{}
+	for _, c := range plan.Changes() {
+		account, err := o.AccountE(c.Account)
+		if err != nil {
+			return err
+		}
+
+		o.Logger.Info(fmt.Sprintf("%s contract %s on account %s", c.Action, c.Contract, c.Account))
+		unlock := o.lockInteraction(false)
+		_, _, err = o.Flowkit.AddContract(
+			ctx,
+			account,
+			flowkit.Script{Code: c.source, Args: c.Arguments, Location: c.location},
+			flowkit.UpdateExistingContract(c.Action == DeploymentUpdate),
+		)
+		unlock()
+		if err != nil {
+			err = errors.Wrapf(err, "could not %s contract %s on account %s", c.Action, c.Contract, c.Account)
+			o.logContractsDeployed(names, err)
+			return err
+		}
+		names = append(names, c.Contract)
+	}
+	o.logContractsDeployed(names, nil)
+
+	if o.LogLevel == output.NoneLog && o.PrintOptions != nil && len(names) > 0 {
+		o.printOutput("deploy contracts", func(w io.Writer) { fmt.Fprintf(w, "%v deploy contracts %s\n", emoji.Scroll, strings.Join(names, ", ")) })
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package overflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentPlan(t *testing.T) {
+	debug, err := os.ReadFile("contracts/Debug.cdc")
+	require.NoError(t, err)
+
+	changedDebug := strings.Replace(string(debug), "access(all) fun id(", "access(all) fun id2() {}\n\n    access(all) fun id(", 1)
+
+	t.Run("Should skip deployed contracts", func(t *testing.T) {
+		o, err := OverflowTesting()
+		require.NoError(t, err)
+
+		plan, err := o.PlanDeployment(context.Background())
+		require.NoError(t, err)
+		require.Len(t, plan.Contracts, 1)
+		assert.Equal(t, "emulator", plan.Network)
+		assert.Equal(t, "Debug", plan.Contracts[0].Contract)
+		assert.Equal(t, "account", plan.Contracts[0].Account)
+		assert.Equal(t, DeploymentSkip, plan.Contracts[0].Action)
+		assert.Empty(t, plan.Changes())
+		assert.Equal(t, 0.0, plan.StorageCost())
+	})
+
+	t.Run("Should plan and apply update with diff", func(t *testing.T) {
+		o, err := OverflowTesting()
+		require.NoError(t, err)
+		updateDebugContract(t, o, changedDebug)
+
+		plan, err := o.PlanDeployment(context.Background())
+		require.NoError(t, err)
+		contract := plan.Contracts[0]
+		assert.Equal(t, DeploymentUpdate, contract.Action)
+		assert.Contains(t, contract.Diff, "-    access(all) fun id2() {}")
+		assert.Less(t, contract.StorageDelta, 0)
+		assert.Contains(t, plan.String(), "update Debug -> account (0xf8d6e0586b0a20c7) storage=-")
+
+		require.NoError(t, o.ApplyPlan(context.Background(), plan))
+
+		plan, err = o.PlanDeployment(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, DeploymentSkip, plan.Contracts[0].Action)
+	})
+
+	t.Run("Should plan and apply create", func(t *testing.T) {
+		o, err := OverflowTesting()
+		require.NoError(t, err)
+		o.Tx(`
+transaction {
+	prepare(signer: auth(RemoveContract) &Account) {
+		signer.contracts.remove(name: "Debug")
+	}
+}`,
+			WithSigner("account"),
+		).AssertSuccess(t)
+
+		plan, err := o.PlanDeployment(context.Background())
+		require.NoError(t, err)
+		contract := plan.Contracts[0]
+		assert.Equal(t, DeploymentCreate, contract.Action)
+		assert.Empty(t, contract.Diff)
+		assert.Equal(t, len(contract.Code), contract.StorageDelta)
+		assert.Greater(t, plan.StorageCost(), 0.0)
+
+		require.NoError(t, o.ApplyPlan(context.Background(), plan))
+		res := o.Script(`
+import "Debug"
+access(all) fun main(): String {
+	return Debug.log("deployed")
+}`)
+		require.NoError(t, res.Err)
+		assert.Equal(t, "deployed", res.Output)
+	})
+
+	t.Run("Should not apply stale plan", func(t *testing.T) {
+		o, err := OverflowTesting()
+		require.NoError(t, err)
+		updateDebugContract(t, o, changedDebug)
+
+		plan, err := o.PlanDeployment(context.Background())
+		require.NoError(t, err)
+
+		updateDebugContract(t, o, string(debug))
+
+		err = o.ApplyPlan(context.Background(), plan)
+		assert.ErrorContains(t, err, "contract Debug on account account has changed since the plan was made")
+	})
+	t.Run("Should log the deployed contracts to the structured logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		o, err := OverflowTesting(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))), WithGlobalPrintOptions())
+		require.NoError(t, err)
+		updateDebugContract(t, o, changedDebug)
+
+		plan, err := o.PlanDeployment(context.Background())
+		require.NoError(t, err)
+		buf.Reset()
+
+		require.NoError(t, o.ApplyPlan(context.Background(), plan))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &record))
+		assert.Equal(t, "output", record["msg"])
+		assert.Equal(t, "deploy contracts", record["name"])
+		assert.Contains(t, record["output"], "deploy contracts Debug")
+	})
+}
@@ -230,6 +230,20 @@ func (overflowEvents OverflowEvents) FilterTempWithdrawDeposit() OverflowEvents
 
 var feeReceipients = []string{"0xf919ee77447b7497", "0x912d5440f7e3769e", "0xe5a8b7f23e8b548f", "0xab086ce9cc29fc80"}
 
+// the fee withdrawn from the payer
+func isFeeWithdrawal(event OverflowEvent, fee float64, payer string) bool {
+	amount, amountOk := event.Fields["amount"].(float64)
+	from, fromOk := event.Fields["from"].(string)
+	return amountOk && fromOk && amount == fee && from == payer
+}
+
+// the fee deposited to one of the fee receipients
+func isFeeDeposit(event OverflowEvent, fee float64) bool {
+	amount, amountOk := event.Fields["amount"].(float64)
+	to, toOk := event.Fields["to"].(string)
+	return amountOk && toOk && amount == fee && slices.Contains(feeReceipients, to)
+}
+
 // Filtter out fee events
 func (overflowEvents OverflowEvents) FilterFees(fee float64, payer string) OverflowEvents {
 	filteredEvents := overflowEvents
@@ -245,10 +259,7 @@ func (overflowEvents OverflowEvents) FilterFees(fee float64, payer string) Overf
 				if !strings.HasSuffix(ftType, "FlowToken.Vault") {
 					continue
 				}
-				amount := value.Fields["amount"].(float64)
-				from, ok := value.Fields["from"].(string)
-
-				if ok && amount == fee && from == payer {
+				if isFeeWithdrawal(value, fee, payer) {
 					continue
 				}
 
@@ -268,10 +279,7 @@ func (overflowEvents OverflowEvents) FilterFees(fee float64, payer string) Overf
 				if !strings.HasSuffix(ftType, "FlowToken.Vault") {
 					continue
 				}
-				amount := value.Fields["amount"].(float64)
-				to, ok := value.Fields["to"].(string)
-
-				if ok && amount == fee && slices.Contains(feeReceipients, to) {
+				if isFeeDeposit(value, fee) {
 					continue
 				}
 				depositEvents = append(depositEvents, value)
@@ -288,10 +296,7 @@ func (overflowEvents OverflowEvents) FilterFees(fee float64, payer string) Overf
 			withDrawnEvents := []OverflowEvent{}
 			for _, value := range events {
 
-				amount := value.Fields["amount"].(float64)
-				from, ok := value.Fields["from"].(string)
-
-				if ok && amount == fee && from == payer {
+				if isFeeWithdrawal(value, fee, payer) {
 					continue
 				}
 
@@ -308,10 +313,7 @@ func (overflowEvents OverflowEvents) FilterFees(fee float64, payer string) Overf
 			depositEvents := []OverflowEvent{}
 			for _, value := range events {
 
-				amount := value.Fields["amount"].(float64)
-				to, ok := value.Fields["to"].(string)
-
-				if ok && amount == fee && slices.Contains(feeReceipients, to) {
+				if isFeeDeposit(value, fee) {
 					continue
 				}
 				depositEvents = append(depositEvents, value)
@@ -340,9 +342,15 @@ func (overflowEvents OverflowEvents) Print(t *testing.T) {
 	if t != nil {
 		t.Helper()
 	}
+	for _, line := range overflowEvents.printLines() {
+		printOrLog(t, line)
+	}
+}
 
+// the lines printed for the events ordered by when they were emitted
+func (overflowEvents OverflowEvents) printLines() []string {
 	events := []OverflowEvent{}
-	printOrLog(t, "=== Events ===")
+	lines := []string{"=== Events ==="}
 	for _, eventList := range overflowEvents {
 		for _, event := range eventList {
 			events = append(events, event)
@@ -354,7 +362,7 @@ func (overflowEvents OverflowEvents) Print(t *testing.T) {
 	})
 
 	for _, event := range events {
-		printOrLog(t, event.Name)
+		lines = append(lines, event.Name)
 		length := 0
 		for key := range event.Fields {
 			keyLength := len(key)
@@ -365,9 +373,10 @@ func (overflowEvents OverflowEvents) Print(t *testing.T) {
 
 		format := fmt.Sprintf("%%%ds -> %%v", length+2)
 		for key, value := range event.Fields {
-			printOrLog(t, fmt.Sprintf(format, key, value))
+			lines = append(lines, fmt.Sprintf(format, key, value))
 		}
 	}
+	return lines
 }
 
 // Filter out events given the sent in filter
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/bjartek/overflow/v2"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -29,3 +31,21 @@ func TestExample(t *testing.T) {
 		assert.Equal(t, 4, int(block.Height))
 	})
 }
+
+func TestParallelExample(t *testing.T) {
+	// in order to run tests in parallel use `ot.RunParallel(t,...)`, each test gets its own overflow with the state after setup_test
+	for _, name := range []string{"Parallel test", "Parallel test 2"} {
+		ot.RunParallel(t, name, func(t *testing.T, o *overflow.OverflowState) {
+			block, err := o.GetLatestBlock(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 4, int(block.Height))
+
+			o.MintFlowTokens("first", 1000.0)
+			require.NoError(t, o.Error)
+
+			block, err = o.GetLatestBlock(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 5, int(block.Height))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package example
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
@@ -12,7 +13,7 @@ var ot *overflow.OverflowTest
 
 func TestMain(m *testing.M) {
 	var err error
-	ot, err = overflow.SetupTest([]overflow.OverflowOption{overflow.WithCoverageReport()}, func(o *overflow.OverflowState) error {
+	ot, err = overflow.SetupTest([]overflow.OverflowOption{overflow.WithCoverageReport(), overflow.WithIsolatedEnvironments()}, func(o *overflow.OverflowState) error {
 		o.MintFlowTokens("first", 1000.0)
 		return nil
 	})
@@ -20,6 +21,10 @@ func TestMain(m *testing.M) {
 		panic(err)
 	}
 	code := m.Run()
-	ot.Teardown()
+	err = ot.TeardownE()
+	if err != nil {
+		fmt.Println(err)
+		code = 1
+	}
 	os.Exit(code)
 }
@@ -0,0 +1,584 @@
+package overflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bjartek/underflow"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+// Fake client
+//
+// OverflowFake is an in memory OverflowClient for unit testing code that depends on overflow without running an emulator.
+// Scripts and transactions are recorded and answered by stubs matched on name and arguments.
+//
+//	fake := NewOverflowFake(WithFakeAccount("alice", "0x01"))
+//	fake.OnScript("get_balance").WithArgs(map[string]interface{}{"address": "alice"}).Return(10.0)
+//	fake.OnTx("transfer").ReturnEvents(OverflowEvents{...})
+//	... run the code under test with fake as the OverflowClient
+//	fake.AssertTxCalled(t, "transfer", map[string]interface{}{"amount": 10.0})
+
+// the name of the network the fake pretends to run on
+const fakeNetwork = "emulator"
+
+// a type representing setting an option on a fake
+type OverflowFakeOption func(*OverflowFake)
+
+// an interaction that was sent to the fake
+type OverflowFakeCall struct {
+	// transaction or script
+	Type string
+
+	// the filename or the inline code of the interaction
+	Name string
+
+	// the named arguments as they were given with WithArg
+	Args map[string]interface{}
+
+	// the logical name of the proposer of a transaction
+	Signer string
+
+	// the logical names of the payload signers of a transaction
+	Authorizers []string
+}
+
+// a stub that answers interactions sent to the fake
+type OverflowFakeStub struct {
+	interactionType string
+	name            string
+	args            map[string]interface{}
+	matcher         func(call OverflowFakeCall) bool
+
+	output interface{}
+	events OverflowEvents
+	err    error
+}
+
+// only answer interactions that have at least these arguments, values are compared after converting to the same type
+func (s *OverflowFakeStub) WithArgs(args map[string]interface{}) *OverflowFakeStub {
+	s.args = args
+	return s
+}
+
+// only answer interactions that the given function accepts
+func (s *OverflowFakeStub) Matching(matcher func(call OverflowFakeCall) bool) *OverflowFakeStub {
+	s.matcher = matcher
+	return s
+}
+
+// the output of a script, cadence values are converted like the real client does
+func (s *OverflowFakeStub) Return(output interface{}) *OverflowFakeStub {
+	s.output = output
+	return s
+}
+
+// the events emitted by a transaction
+func (s *OverflowFakeStub) ReturnEvents(events OverflowEvents) *OverflowFakeStub {
+	s.events = events
+	return s
+}
+
+// fail the interaction with the given error
+func (s *OverflowFakeStub) ReturnError(err error) *OverflowFakeStub {
+	s.err = err
+	return s
+}
+
+func (s *OverflowFakeStub) matches(call OverflowFakeCall) bool {
+	if s.interactionType != call.Type || s.name != call.Name {
+		return false
+	}
+	if !argsMatch(s.args, call.Args) {
+		return false
+	}
+	return s.matcher == nil || s.matcher(call)
+}
+
+func argsMatch(expected map[string]interface{}, actual map[string]interface{}) bool {
+	for name, value := range expected {
+		actualValue, ok := actual[name]
+		if !ok || !assert.ObjectsAreEqualValues(value, actualValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// OverflowFake is a programmable in memory implementation of OverflowClient, see NewOverflowFake
+type OverflowFake struct {
+	// the state used to resolve accounts, contracts and parse events
+	Overflow *OverflowState
+
+	mutex        sync.Mutex
+	stubs        []*OverflowFakeStub
+	calls        []OverflowFakeCall
+	transactions map[flow.Identifier]*OverflowResult
+	height       uint64
+}
+
+var _ OverflowClient = (*OverflowFake)(nil)
+
+// add an account with the given logical name and address
+func WithFakeAccount(name string, address string) OverflowFakeOption {
+	return func(f *OverflowFake) {
+		seed := sha256.Sum256([]byte(name))
+		privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, seed[:])
+		if err != nil {
+			f.Overflow.Error = err
+			return
+		}
+		f.Overflow.State.Accounts().AddOrUpdate(&accounts.Account{
+			Name:    f.Overflow.accountName(name),
+			Address: flow.HexToAddress(address),
+			Key:     accounts.NewHexKeyFromPrivateKey(0, crypto.SHA3_256, privateKey),
+		})
+	}
+}
+
+// add a contract with the given name deployed to the given address
+func WithFakeContract(name string, address string) OverflowFakeOption {
+	return func(f *OverflowFake) {
+		f.Overflow.State.Contracts().AddOrUpdate(config.Contract{
+			Name:     name,
+			Location: fmt.Sprintf("%s.cdc", name),
+			Aliases:  config.Aliases{{Network: fakeNetwork, Address: flow.HexToAddress(address)}},
+		})
+	}
+}
+
+// NewOverflowFake creates a fake client with an in memory state that only has the service account
+func NewOverflowFake(opts ...OverflowFakeOption) *OverflowFake {
+	state, err := flowkit.Init(&afero.Afero{Fs: afero.NewMemMapFs()}, crypto.ECDSA_P256, crypto.SHA3_256)
+	o := &OverflowState{
+		State:                        state,
+		Network:                      config.Network{Name: fakeNetwork},
+		PrependNetworkToAccountNames: true,
+		ServiceAccountSuffix:         "account",
+		UnderflowOptions:             underflow.Options{},
+		EmulatorLog:                  newOverflowEmulatorLog(),
+		Error:                        err,
+	}
+	f := &OverflowFake{
+		Overflow:     o,
+		transactions: map[flow.Identifier]*OverflowResult{},
+	}
+	if err != nil {
+		return f
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// answer scripts with the given filename or code
+func (f *OverflowFake) OnScript(name string) *OverflowFakeStub {
+	return f.on(interactionScript, name)
+}
+
+// answer transactions with the given filename or code
+func (f *OverflowFake) OnTx(name string) *OverflowFakeStub {
+	return f.on(interactionTransaction, name)
+}
+
+func (f *OverflowFake) on(interactionType string, name string) *OverflowFakeStub {
+	stub := &OverflowFakeStub{interactionType: interactionType, name: name}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.stubs = append(f.stubs, stub)
+	return stub
+}
+
+// all interactions sent to the fake in order
+func (f *OverflowFake) Calls() []OverflowFakeCall {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]OverflowFakeCall{}, f.calls...)
+}
+
+// the interactions with the given type, name and at least the given arguments
+func (f *OverflowFake) callsMatching(interactionType string, name string, args map[string]interface{}) []OverflowFakeCall {
+	result := []OverflowFakeCall{}
+	for _, call := range f.Calls() {
+		if call.Type == interactionType && call.Name == name && argsMatch(args, call.Args) {
+			result = append(result, call)
+		}
+	}
+	return result
+}
+
+// Assert that a transaction with the given name and at least the given arguments was sent
+func (f *OverflowFake) AssertTxCalled(t *testing.T, name string, args map[string]interface{}) *OverflowFake {
+	t.Helper()
+	if len(f.callsMatching(interactionTransaction, name, args)) == 0 {
+		assert.Fail(t, fmt.Sprintf("transaction %s was not sent with args %v", name, args), f.callsString())
+	}
+	return f
+}
+
+// Assert that a script with the given name and at least the given arguments was run
+func (f *OverflowFake) AssertScriptCalled(t *testing.T, name string, args map[string]interface{}) *OverflowFake {
+	t.Helper()
+	if len(f.callsMatching(interactionScript, name, args)) == 0 {
+		assert.Fail(t, fmt.Sprintf("script %s was not run with args %v", name, args), f.callsString())
+	}
+	return f
+}
+
+// Assert the number of scripts and transactions with the given name
+func (f *OverflowFake) AssertCallCount(t *testing.T, name string, count int) *OverflowFake {
+	t.Helper()
+	actual := len(f.callsMatching(interactionScript, name, nil)) + len(f.callsMatching(interactionTransaction, name, nil))
+	assert.Equal(t, count, actual, "number of calls to %s", name)
+	return f
+}
+
+// Assert that nothing has been sent to the fake
+func (f *OverflowFake) AssertNoCalls(t *testing.T) *OverflowFake {
+	t.Helper()
+	assert.Empty(t, f.Calls(), f.callsString())
+	return f
+}
+
+func (f *OverflowFake) callsString() string {
+	lines := []string{"calls:"}
+	for _, call := range f.Calls() {
+		lines = append(lines, fmt.Sprintf("%s %s %v", call.Type, call.Name, call.Args))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// apply the options and record the call, returns the first stub that matches
+func (f *OverflowFake) record(interactionType string, name string, opts []OverflowInteractionOption) (*OverflowInteractionBuilder, *OverflowFakeStub) {
+	oib := &OverflowInteractionBuilder{
+		Ctx:            context.Background(),
+		Overflow:       f.Overflow,
+		Arguments:      []cadence.Value{},
+		PayloadSigners: []*accounts.Account{},
+		NamedArgs:      map[string]interface{}{},
+		FileName:       name,
+		Name:           name,
+	}
+	for _, opt := range opts {
+		opt(oib)
+	}
+
+	call := OverflowFakeCall{Type: interactionType, Name: name, Args: oib.NamedArgs}
+	if oib.Proposer != nil {
+		call.Signer = f.Overflow.logicalAccountName(oib.Proposer.Name)
+	}
+	for _, signer := range oib.PayloadSigners {
+		call.Authorizers = append(call.Authorizers, f.Overflow.logicalAccountName(signer.Name))
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls = append(f.calls, call)
+	for _, stub := range f.stubs {
+		if stub.matches(call) {
+			return oib, stub
+		}
+	}
+	return oib, nil
+}
+
+func (f *OverflowFake) Script(filename string, opts ...OverflowInteractionOption) *OverflowScriptResult {
+	oib, stub := f.record(interactionScript, filename, opts)
+	result := &OverflowScriptResult{Input: oib}
+	switch {
+	case oib.Error != nil:
+		result.Err = oib.Error
+	case stub == nil:
+		result.Err = fmt.Errorf("no fake for script %s", filename)
+	default:
+		result.Err = stub.err
+		if value, ok := stub.output.(cadence.Value); ok {
+			result.Result = value
+			result.Output = underflow.CadenceValueToInterfaceWithOption(value, f.Overflow.UnderflowOptions)
+		} else {
+			result.Output = stub.output
+		}
+	}
+	return result
+}
+
+func (f *OverflowFake) ScriptFN(outerOpts ...OverflowInteractionOption) OverflowScriptFunction {
+	return func(filename string, opts ...OverflowInteractionOption) *OverflowScriptResult {
+		return f.Script(filename, append(outerOpts, opts...)...)
+	}
+}
+
+func (f *OverflowFake) ScriptFileNameFN(filename string, outerOpts ...OverflowInteractionOption) OverflowScriptOptsFunction {
+	return func(opts ...OverflowInteractionOption) *OverflowScriptResult {
+		return f.Script(filename, append(outerOpts, opts...)...)
+	}
+}
+
+func (f *OverflowFake) Tx(filename string, opts ...OverflowInteractionOption) *OverflowResult {
+	oib, stub := f.record(interactionTransaction, filename, opts)
+
+	f.mutex.Lock()
+	f.height++
+	height := f.height
+	f.mutex.Unlock()
+
+	result := &OverflowResult{
+		Id:               fakeIdentifier("transaction", height),
+		Name:             filename,
+		Events:           OverflowEvents{},
+		Fee:              map[string]interface{}{},
+		Meter:            &OverflowMeter{},
+		Transaction:      &flow.Transaction{Script: []byte(filename), ReferenceBlockID: fakeIdentifier("block", height)},
+		UnderflowOptions: f.Overflow.UnderflowOptions,
+		overflow:         f.Overflow,
+	}
+	if oib.Proposer != nil {
+		result.Transaction.Payer = oib.Proposer.Address
+	}
+	switch {
+	case oib.Error != nil:
+		result.Err = oib.Error
+	case stub == nil:
+		result.Err = fmt.Errorf("no fake for transaction %s", filename)
+	default:
+		result.Err = stub.err
+		if stub.events != nil {
+			result.Events = stub.events
+		}
+	}
+
+	f.mutex.Lock()
+	f.transactions[result.Id] = result
+	f.mutex.Unlock()
+
+	if result.Err != nil && oib.StopOnError != nil && *oib.StopOnError {
+		panic(result.Err)
+	}
+	return result
+}
+
+func (f *OverflowFake) TxFN(outerOpts ...OverflowInteractionOption) OverflowTransactionFunction {
+	return func(filename string, opts ...OverflowInteractionOption) *OverflowResult {
+		return f.Tx(filename, append(outerOpts, opts...)...)
+	}
+}
+
+func (f *OverflowFake) TxFileNameFN(filename string, outerOpts ...OverflowInteractionOption) OverflowTransactionOptsFunction {
+	return func(opts ...OverflowInteractionOption) *OverflowResult {
+		return f.Tx(filename, append(outerOpts, opts...)...)
+	}
+}
+
+// flix interactions are recorded and stubbed like other interactions with the flix query as the name
+func (f *OverflowFake) FlixTx(filename string, opts ...OverflowInteractionOption) *OverflowResult {
+	return f.Tx(filename, opts...)
+}
+
+func (f *OverflowFake) FlixTxFN(outerOpts ...OverflowInteractionOption) OverflowTransactionFunction {
+	return f.TxFN(outerOpts...)
+}
+
+func (f *OverflowFake) FlixTxFileNameFN(filename string, outerOpts ...OverflowInteractionOption) OverflowTransactionOptsFunction {
+	return f.TxFileNameFN(filename, outerOpts...)
+}
+
+func (f *OverflowFake) FlixScript(filename string, opts ...OverflowInteractionOption) *OverflowScriptResult {
+	return f.Script(filename, opts...)
+}
+
+func (f *OverflowFake) FlixScriptFN(outerOpts ...OverflowInteractionOption) OverflowScriptFunction {
+	return f.ScriptFN(outerOpts...)
+}
+
+func (f *OverflowFake) FlixScriptFileNameFN(filename string, outerOpts ...OverflowInteractionOption) OverflowScriptOptsFunction {
+	return f.ScriptFileNameFN(filename, outerOpts...)
+}
+
+// a stable identifier for fake transactions and blocks
+func fakeIdentifier(kind string, height uint64) flow.Identifier {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, height)
+	return flow.Identifier(sha256.Sum256(append([]byte(kind), bytes...)))
+}
+
+func (f *OverflowFake) block(height uint64) *flow.Block {
+	block := &flow.Block{}
+	block.ID = fakeIdentifier("block", height)
+	block.Height = height
+	block.Timestamp = time.Unix(int64(height), 0).UTC()
+	if height > 0 {
+		block.ParentID = fakeIdentifier("block", height-1)
+	}
+	return block
+}
+
+// every transaction sent to the fake is in its own block
+func (f *OverflowFake) GetLatestBlock(ctx context.Context) (*flow.Block, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.block(f.height), nil
+}
+
+func (f *OverflowFake) GetBlockAtHeight(ctx context.Context, height uint64) (*flow.Block, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if height > f.height {
+		return nil, fmt.Errorf("block at height %d not found", height)
+	}
+	return f.block(height), nil
+}
+
+func (f *OverflowFake) GetBlockById(ctx context.Context, blockId string) (*flow.Block, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	id := flow.HexToID(blockId)
+	for height := uint64(0); height <= f.height; height++ {
+		if fakeIdentifier("block", height) == id {
+			return f.block(height), nil
+		}
+	}
+	return nil, fmt.Errorf("block with id %s not found", blockId)
+}
+
+func (f *OverflowFake) GetTransactionById(ctx context.Context, id flow.Identifier) (*flow.Transaction, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	result, ok := f.transactions[id]
+	if !ok {
+		return nil, fmt.Errorf("transaction with id %s not found", id)
+	}
+	return result.Transaction, nil
+}
+
+func (f *OverflowFake) GetOverflowTransactionById(ctx context.Context, id flow.Identifier) (*OverflowTransaction, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	result, ok := f.transactions[id]
+	if !ok {
+		return nil, fmt.Errorf("transaction with id %s not found", id)
+	}
+	events := []OverflowEvent{}
+	for _, list := range result.Events {
+		events = append(events, list...)
+	}
+	return &OverflowTransaction{
+		Id:             id.String(),
+		BlockId:        result.Transaction.ReferenceBlockID.String(),
+		Status:         flow.TransactionStatusSealed.String(),
+		Error:          result.Err,
+		Events:         events,
+		Script:         result.Transaction.Script,
+		Payer:          fmt.Sprintf("0x%s", result.Transaction.Payer.String()),
+		BalanceChanges: result.Events.BalanceChanges(0, ""),
+	}, nil
+}
+
+// the fake does not have system chunk transactions so this is always empty
+func (f *OverflowFake) GetTransactionsByBlockId(ctx context.Context, id flow.Identifier) ([]*flow.Transaction, []*flow.TransactionResult, error) {
+	return []*flow.Transaction{}, []*flow.TransactionResult{}, nil
+}
+
+func (f *OverflowFake) QualifiedIdentifierFromSnakeCase(typeName string) (string, error) {
+	return f.Overflow.QualifiedIdentifierFromSnakeCase(typeName)
+}
+
+func (f *OverflowFake) QualifiedIdentifier(contract string, name string) (string, error) {
+	return f.Overflow.QualifiedIdentifier(contract, name)
+}
+
+// contracts are not deployed anywhere, add them WithFakeContract to resolve identifiers
+func (f *OverflowFake) AddContract(ctx context.Context, name string, code []byte, args []cadence.Value, filename string, update bool) error {
+	return nil
+}
+
+func (f *OverflowFake) GetNetwork() string {
+	return f.Overflow.GetNetwork()
+}
+
+func (f *OverflowFake) AccountE(key string) (*accounts.Account, error) {
+	return f.Overflow.AccountE(key)
+}
+
+func (f *OverflowFake) Address(key string) string {
+	return f.Overflow.Address(key)
+}
+
+func (f *OverflowFake) Account(key string) *accounts.Account {
+	return f.Overflow.Account(key)
+}
+
+func (f *OverflowFake) AccountPublicKey(name string) (string, error) {
+	return f.Overflow.AccountPublicKey(name)
+}
+
+// the account has the address of the fake account and no balance, keys or contracts
+func (f *OverflowFake) GetAccount(ctx context.Context, key string) (*flow.Account, error) {
+	account, err := f.Overflow.AccountE(key)
+	if err != nil {
+		return nil, err
+	}
+	return &flow.Account{Address: account.Address, Contracts: map[string][]byte{}}, nil
+}
+
+func (f *OverflowFake) SignUserMessage(account string, message string) (string, error) {
+	return f.Overflow.SignUserMessage(account, message)
+}
+
+// uploads are accepted and nothing is stored
+func (f *OverflowFake) UploadFile(filename string, accountName string) error {
+	return nil
+}
+
+func (f *OverflowFake) DownloadAndUploadFile(url string, accountName string) error {
+	return nil
+}
+
+func (f *OverflowFake) DownloadImageAndUploadAsDataUrl(url, accountName string) error {
+	return nil
+}
+
+func (f *OverflowFake) UploadImageAsDataUrl(filename string, accountName string) error {
+	return nil
+}
+
+func (f *OverflowFake) UploadString(content string, accountName string) error {
+	return nil
+}
+
+func (f *OverflowFake) GetFreeCapacity(accountName string) int {
+	return 0
+}
+
+func (f *OverflowFake) MintFlowTokens(accountName string, amount float64) *OverflowState {
+	return f.Overflow
+}
+
+func (f *OverflowFake) FillUpStorage(accountName string) *OverflowState {
+	return f.Overflow
+}
+
+func (f *OverflowFake) ParseEvents(events []flow.Event) (OverflowEvents, OverflowEvent) {
+	return f.Overflow.ParseEvents(events)
+}
+
+func (f *OverflowFake) ParseEventsWithIdPrefix(events []flow.Event, idPrefix string) (OverflowEvents, OverflowEvent) {
+	return f.Overflow.ParseEventsWithIdPrefix(events, idPrefix)
+}
+
+func (f *OverflowFake) CreateOverflowTransaction(blockId string, transactionResult flow.TransactionResult, transaction flow.Transaction, txIndex int) (*OverflowTransaction, error) {
+	return f.Overflow.CreateOverflowTransaction(blockId, transactionResult, transaction, txIndex)
+}
@@ -0,0 +1,71 @@
+package overflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverflowFake(t *testing.T) {
+	fake := NewOverflowFake(WithFakeAccount("alice", "0x01"), WithFakeAccount("bob", "0x02"), WithFakeContract("Debug", "0xf8d6e0586b0a20c7"))
+	require.NoError(t, fake.Overflow.Error)
+
+	var client OverflowClient = fake
+
+	t.Run("Should resolve accounts and contracts", func(t *testing.T) {
+		assert.Equal(t, "0x0000000000000001", client.Address("alice"))
+		assert.Equal(t, "emulator", client.GetNetwork())
+
+		identifier, err := client.QualifiedIdentifier("Debug", "Foo")
+		require.NoError(t, err)
+		assert.Equal(t, "A.f8d6e0586b0a20c7.Debug.Foo", identifier)
+	})
+
+	t.Run("Should answer scripts from stubs", func(t *testing.T) {
+		fake.OnScript("balance").WithArgs(map[string]interface{}{"address": "alice"}).Return(10.0)
+		fake.OnScript("balance").Return(cadence.UFix64(100000000))
+		fake.OnScript("broken").ReturnError(fmt.Errorf("boom"))
+
+		assert.Equal(t, 10.0, client.Script("balance", WithArg("address", "alice")).Output)
+		assert.Equal(t, 1.0, client.Script("balance", WithArg("address", "bob")).Output)
+		assert.EqualError(t, client.Script("broken").Err, "boom")
+		assert.EqualError(t, client.Script("missing").Err, "no fake for script missing")
+
+		fake.AssertScriptCalled(t, "balance", map[string]interface{}{"address": "bob"}).
+			AssertCallCount(t, "balance", 2)
+	})
+
+	t.Run("Should record transactions", func(t *testing.T) {
+		fake.OnTx("transfer").ReturnEvents(OverflowEvents{
+			"A.ee82856bf20e2aa6.FungibleToken.Withdrawn": []OverflowEvent{
+				{Fields: map[string]interface{}{"type": "A.0ae53cb6e3f42a79.FlowToken.Vault", "amount": 10.0, "from": "0x0000000000000001"}},
+			},
+		})
+
+		res := client.Tx("transfer", WithSigner("alice"), WithPayloadSigner("bob"), WithArg("amount", 10))
+		res.AssertSuccess(t).
+			AssertEmitEventName(t, "FungibleToken.Withdrawn").
+			AssertBalanceChange(t, "alice", "FlowToken.Vault", -10.0)
+
+		fake.AssertTxCalled(t, "transfer", map[string]interface{}{"amount": 10.0})
+		calls := fake.Calls()
+		last := calls[len(calls)-1]
+		assert.Equal(t, "alice", last.Signer)
+		assert.Equal(t, []string{"bob"}, last.Authorizers)
+
+		tx, err := client.GetOverflowTransactionById(context.Background(), res.Id)
+		require.NoError(t, err)
+		assert.Len(t, tx.Events, 1)
+		assert.Equal(t, -10.0, tx.BalanceChanges.Get("0x0000000000000001", "FlowToken.Vault"))
+
+		block, err := client.GetLatestBlock(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), block.Height)
+
+		client.Tx("transfer", WithSigner("carol")).AssertFailure(t, "could not find account with name emulator-carol")
+	})
+}
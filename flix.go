@@ -1,5 +1,23 @@
 package overflow
 
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/model/encoding/rlp"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/gateway"
+	"github.com/pkg/errors"
+)
+
 // run a script with the given code/filanem an options
 func (o *OverflowState) FlixScript(filename string, opts ...OverflowInteractionOption) *OverflowScriptResult {
 	interaction := o.BuildInteraction(filename, "flix", opts...)
@@ -7,10 +25,10 @@ func (o *OverflowState) FlixScript(filename string, opts ...OverflowInteractionO
 	result := interaction.runScript()
 
 	if interaction.PrintOptions != nil && !interaction.NoLog {
-		result.Print()
+		o.printOutput(interaction.Name, result.fprint)
 	}
 	if o.StopOnError && result.Err != nil {
-		result.PrintArguments(nil)
+		o.printOutput(interaction.Name, result.fprintArguments)
 		panic(result.Err)
 	}
 	return result
@@ -55,3 +73,293 @@ func (o *OverflowState) FlixTx(filename string, opts ...OverflowInteractionOptio
 
 	return o.sendTx(interaction)
 }
+
+// the FLIX template for a query, a template in the flix folder named after the query is used before asking flixkit
+func (o *OverflowState) rawFlix(ctx context.Context, query string) (string, error) {
+	if o.FlixBasePath != "" {
+		template, err := o.State.ReaderWriter().ReadFile(fmt.Sprintf("%s/%s.json", o.FlixBasePath, query))
+		if err == nil {
+			return string(template), nil
+		}
+	}
+	template, _, err := o.Flixkit.GetTemplate(ctx, query)
+	return template, err
+}
+
+// the cadence of a FLIX template with the imports for the network overflow is connected to
+// version 1.1.0 templates are verified before they are used, templates that cannot be verified are not used on mainnet
+func (o *OverflowState) flixCadence(ctx context.Context, query string) (string, error) {
+	raw, err := o.rawFlix(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	var template flixTemplate
+	err = json.Unmarshal([]byte(raw), &template)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not parse flix %s", query)
+	}
+
+	if template.FVersion != "1.1.0" {
+		if o.Network.Name == config.MainnetNetwork.Name {
+			return "", fmt.Errorf("flix %s has version %s that cannot be verified, only verified templates are used on mainnet", query, template.FVersion)
+		}
+		flix, err := o.Flixkit.GetTemplateAndReplaceImports(ctx, raw, o.Network.Name)
+		if err != nil {
+			return "", err
+		}
+		return flix.Cadence, nil
+	}
+
+	err = o.verifyFlix(ctx, &template, o.Network.Name)
+	if err != nil {
+		return "", errors.Wrapf(err, "flix %s is not verified", query)
+	}
+	cadence, _ := template.cadenceForNetwork(o.Network.Name)
+	return cadence, nil
+}
+
+// verify that the id, the cadence and the dependency pins of a template match the contracts on the network
+// on mainnet all pins must be present, on other networks missing pins are skipped
+func (o *OverflowState) verifyFlix(ctx context.Context, template *flixTemplate, network string) error {
+	strict := network == config.MainnetNetwork.Name
+
+	id, err := template.calculateID()
+	if err != nil {
+		return err
+	}
+	if id != template.ID {
+		return fmt.Errorf("the id %s does not match the content of the template", template.ID)
+	}
+
+	cadence, ok := template.cadenceForNetwork(network)
+	if !ok {
+		return fmt.Errorf("the addresses of all dependencies on network %s are not in the template", network)
+	}
+	pinned := false
+	for _, pin := range template.Data.Cadence.NetworkPins {
+		if pin.Network != network {
+			continue
+		}
+		pinned = true
+		if pin.PinSelf != sha3Hex(cadence) {
+			return fmt.Errorf("the cadence does not match its pin for network %s", network)
+		}
+	}
+	if !pinned && strict {
+		return fmt.Errorf("the cadence is not pinned for network %s", network)
+	}
+
+	for _, dependency := range template.Data.Dependencies {
+		for _, contract := range dependency.Contracts {
+			for _, dependencyNetwork := range contract.Networks {
+				if dependencyNetwork.Network != network {
+					continue
+				}
+				if dependencyNetwork.DependencyPin == nil {
+					if strict {
+						return fmt.Errorf("dependency %s is not pinned for network %s", contract.Contract, network)
+					}
+					continue
+				}
+				pin, err := flixDependencyPin(ctx, o.Flowkit.Gateway(), network, flow.HexToAddress(dependencyNetwork.Address), contract.Contract)
+				if err != nil {
+					return err
+				}
+				if pin.Pin != dependencyNetwork.DependencyPin.Pin {
+					return fmt.Errorf("dependency %s at %s does not match its pin for network %s, the contract or one of its imports has changed", contract.Contract, dependencyNetwork.Address, network)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// FLIX templates of version 1.1.0 are verified before they are used, flixkit does not verify templates so the id and pins are calculated here
+
+type flixTemplate struct {
+	FType    string   `json:"f_type"`
+	FVersion string   `json:"f_version"`
+	ID       string   `json:"id"`
+	Data     flixData `json:"data"`
+}
+
+type flixData struct {
+	Type         string           `json:"type"`
+	Interface    string           `json:"interface"`
+	Messages     []flixMessage    `json:"messages"`
+	Cadence      flixCadence      `json:"cadence"`
+	Dependencies []flixDependency `json:"dependencies"`
+	Parameters   []flixParameter  `json:"parameters"`
+	Output       *flixParameter   `json:"output,omitempty"`
+}
+
+type flixMessage struct {
+	Key  string     `json:"key"`
+	I18n []flixI18n `json:"i18n"`
+}
+
+type flixI18n struct {
+	Tag         string `json:"tag"`
+	Translation string `json:"translation"`
+}
+
+type flixCadence struct {
+	Body        string           `json:"body"`
+	NetworkPins []flixNetworkPin `json:"network_pins"`
+}
+
+type flixNetworkPin struct {
+	Network string `json:"network"`
+	PinSelf string `json:"pin_self"`
+}
+
+type flixDependency struct {
+	Contracts []flixContract `json:"contracts"`
+}
+
+type flixContract struct {
+	Contract string        `json:"contract"`
+	Networks []flixNetwork `json:"networks"`
+}
+
+type flixNetwork struct {
+	Network                  string         `json:"network"`
+	Address                  string         `json:"address"`
+	DependencyPinBlockHeight uint64         `json:"dependency_pin_block_height"`
+	DependencyPin            *flixPinDetail `json:"dependency_pin,omitempty"`
+}
+
+type flixPinDetail struct {
+	Pin                string          `json:"pin"`
+	PinSelf            string          `json:"pin_self"`
+	PinContractName    string          `json:"pin_contract_name"`
+	PinContractAddress string          `json:"pin_contract_address"`
+	Imports            []flixPinDetail `json:"imports"`
+}
+
+type flixParameter struct {
+	Label    string        `json:"label"`
+	Index    int           `json:"index"`
+	Type     string        `json:"type"`
+	Messages []flixMessage `json:"messages"`
+}
+
+// the body with the imports replaced by the addresses of the dependencies on the given network
+func (t *flixTemplate) cadenceForNetwork(network string) (string, bool) {
+	body := t.Data.Cadence.Body
+	for _, dependency := range t.Data.Dependencies {
+		for _, contract := range dependency.Contracts {
+			address := ""
+			for _, n := range contract.Networks {
+				if n.Network == network {
+					address = n.Address
+				}
+			}
+			if address == "" {
+				return "", false
+			}
+			importRegexp := regexp.MustCompile(fmt.Sprintf(`import\s*"%s"`, regexp.QuoteMeta(contract.Contract)))
+			body = importRegexp.ReplaceAllLiteralString(body, fmt.Sprintf("import %s from %s", contract.Contract, address))
+		}
+	}
+	return body, true
+}
+
+func flixMessagesRlp(messages []flixMessage) []interface{} {
+	values := []interface{}{}
+	for _, message := range messages {
+		translations := []interface{}{}
+		for _, i18n := range message.I18n {
+			translations = append(translations, []interface{}{sha3Hex(i18n.Tag), sha3Hex(i18n.Translation)})
+		}
+		values = append(values, []interface{}{sha3Hex(message.Key), translations})
+	}
+	return values
+}
+
+// calculate the id of the template, it is the hash of the rlp encoding of the hashed fields of the template
+func (t *flixTemplate) calculateID() (string, error) {
+	dependencies := []interface{}{}
+	for _, dependency := range t.Data.Dependencies {
+		contracts := []interface{}{}
+		for _, contract := range dependency.Contracts {
+			networks := []interface{}{}
+			for _, network := range contract.Networks {
+				values := []interface{}{sha3Hex(network.Network)}
+				if network.DependencyPin != nil {
+					values = append(values, sha3Hex(network.DependencyPin.Pin))
+				}
+				networks = append(networks, values)
+			}
+			contracts = append(contracts, []interface{}{sha3Hex(contract.Contract), networks})
+		}
+		dependencies = append(dependencies, []interface{}{contracts})
+	}
+
+	// flixkit encodes the parameters ordered by their index
+	ordered := append([]flixParameter{}, t.Data.Parameters...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+	parameters := []interface{}{}
+	for _, parameter := range ordered {
+		parameters = append(parameters, []interface{}{
+			sha3Hex(parameter.Label),
+			[]interface{}{sha3Hex(fmt.Sprint(parameter.Index)), sha3Hex(parameter.Type), flixMessagesRlp(parameter.Messages)},
+		})
+	}
+
+	encoded, err := rlp.NewMarshaler().Marshal([]interface{}{
+		sha3Hex(t.FType),
+		sha3Hex(t.FVersion),
+		sha3Hex(t.Data.Type),
+		sha3Hex(t.Data.Interface),
+		flixMessagesRlp(t.Data.Messages),
+		sha3Hex(t.Data.Cadence.Body),
+		dependencies,
+		parameters,
+	})
+	if err != nil {
+		return "", err
+	}
+	return sha3Hex(hex.EncodeToString(encoded)), nil
+}
+
+// the pin of a deployed contract, it is the hash of the pin_self of the contract and the contracts it imports directly like flixkit calculates it
+func flixDependencyPin(ctx context.Context, gw gateway.Gateway, network string, address flow.Address, name string) (*flixPinDetail, error) {
+	account, err := gw.GetAccount(ctx, address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get account %s to pin contract %s", address.HexWithPrefix(), name)
+	}
+	code, ok := account.Contracts[name]
+	if !ok {
+		return nil, fmt.Errorf("contract %s is not deployed to %s on network %s", name, address.HexWithPrefix(), network)
+	}
+
+	pin := &flixPinDetail{
+		PinSelf:            sha3Hex(string(code)),
+		PinContractName:    name,
+		PinContractAddress: address.HexWithPrefix(),
+		Imports:            []flixPinDetail{},
+	}
+	pinSelfs := []string{pin.PinSelf}
+
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse contract %s", name)
+	}
+	for _, declaration := range program.ImportDeclarations() {
+		location, ok := declaration.Location.(common.AddressLocation)
+		if !ok {
+			continue
+		}
+		for _, identifier := range declaration.Identifiers {
+			imported, err := flixDependencyPin(ctx, gw, network, flow.Address(location.Address), identifier.Identifier)
+			if err != nil {
+				return nil, err
+			}
+			pin.Imports = append(pin.Imports, *imported)
+			pinSelfs = append(pinSelfs, imported.PinSelf)
+		}
+	}
+	pin.Pin = sha3Hex(strings.Join(pinSelfs, ""))
+	return pin, nil
+}
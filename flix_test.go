@@ -0,0 +1,108 @@
+package overflow
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlixFolder(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	o.FlixBasePath = t.TempDir()
+	require.NoError(t, o.GenerateFlixFile("../testdata/flix/mint_tokens", o.FlixBasePath+"/mint-tokens.json", "emulator"))
+	require.NoError(t, o.GenerateFlixFile("../testdata/flix/balance", o.FlixBasePath+"/balance.json", "emulator"))
+
+	generated, err := o.GenerateFlix("../testdata/flix/mint_tokens", "emulator")
+	require.NoError(t, err)
+	var template flixTemplate
+	require.NoError(t, json.Unmarshal([]byte(generated), &template))
+
+	// change the template and give it a valid id so that only the pins are wrong
+	tampered := func(change func(template *flixTemplate)) *flixTemplate {
+		data, err := json.Marshal(&template)
+		require.NoError(t, err)
+		var result flixTemplate
+		require.NoError(t, json.Unmarshal(data, &result))
+		change(&result)
+		result.ID, err = result.calculateID()
+		require.NoError(t, err)
+		return &result
+	}
+
+	t.Run("transaction from the flix folder", func(t *testing.T) {
+		o.FlixTx("mint-tokens",
+			WithSignerServiceAccount(),
+			WithArg("recipient", "first"),
+			WithArg("amount", 1.0),
+		).AssertSuccess(t).AssertEmitEventName(t, "FlowToken.TokensMinted")
+	})
+
+	t.Run("script from the flix folder", func(t *testing.T) {
+		result := o.FlixScript("balance", WithArg("account", "first"))
+		require.NoError(t, result.Err)
+		assert.NotNil(t, result.Output)
+	})
+
+	t.Run("changed template is not run", func(t *testing.T) {
+		changed := tampered(func(*flixTemplate) {})
+		changed.Data.Messages = nil
+		data, err := json.Marshal(changed)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(o.FlixBasePath+"/changed.json", data, 0o644))
+
+		o.FlixTx("changed",
+			WithSignerServiceAccount(),
+			WithArg("recipient", "first"),
+			WithArg("amount", 1.0),
+		).AssertFailure(t, "flix changed is not verified: the id "+changed.ID+" does not match the content of the template")
+	})
+
+	t.Run("changed cadence", func(t *testing.T) {
+		changed := tampered(func(template *flixTemplate) {
+			template.Data.Cadence.Body = template.Data.Cadence.Body + "\n"
+		})
+		assert.ErrorContains(t, o.verifyFlix(context.Background(), changed, "emulator"), "the cadence does not match its pin for network emulator")
+	})
+
+	t.Run("changed dependency", func(t *testing.T) {
+		pin, err := flixDependencyPin(context.Background(), o.Flowkit.Gateway(), "emulator", o.FlowAddress("FlowToken"), "FlowToken")
+		require.NoError(t, err)
+		assert.NotEmpty(t, pin.Imports)
+
+		pinned := tampered(func(template *flixTemplate) {
+			template.Data.Dependencies[1].Contracts[0].Networks[0].DependencyPin = pin
+		})
+		assert.NoError(t, o.verifyFlix(context.Background(), pinned, "emulator"))
+
+		changed := tampered(func(template *flixTemplate) {
+			template.Data.Dependencies[1].Contracts[0].Networks[0].DependencyPin = &flixPinDetail{Pin: sha3Hex("changed")}
+		})
+		assert.ErrorContains(t, o.verifyFlix(context.Background(), changed, "emulator"), "dependency FlowToken at 0x0ae53cb6e3f42a79 does not match its pin for network emulator")
+	})
+
+	t.Run("missing pins are not allowed on mainnet", func(t *testing.T) {
+		unpinned := tampered(func(template *flixTemplate) {
+			for _, network := range []string{"mainnet", "testnet"} {
+				for _, dependency := range template.Data.Dependencies {
+					for index, contract := range dependency.Contracts {
+						address, err := o.contractAddress(contract.Contract, config.Network{Name: network})
+						require.NoError(t, err)
+						dependency.Contracts[index].Networks = append(contract.Networks, flixNetwork{Network: network, Address: address.HexWithPrefix()})
+					}
+				}
+				cadence, ok := template.cadenceForNetwork(network)
+				require.True(t, ok)
+				template.Data.Cadence.NetworkPins = append(template.Data.Cadence.NetworkPins, flixNetworkPin{Network: network, PinSelf: sha3Hex(cadence)})
+			}
+		})
+		assert.ErrorContains(t, o.verifyFlix(context.Background(), unpinned, "mainnet"), "dependency FungibleToken is not pinned for network mainnet")
+		assert.NoError(t, o.verifyFlix(context.Background(), unpinned, "testnet"))
+	})
+}
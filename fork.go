@@ -0,0 +1,87 @@
+package overflow
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/storage/remote"
+	"github.com/onflow/flow-emulator/storage/sqlite"
+	"github.com/onflow/flow-go-sdk"
+	flowgo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Fork
+//
+// Overflow can start the embedded emulator on top of the state of another network, registers are fetched lazily from the access node of that network
+
+// the chain id of the network we fork from, the remote access node has to report the same chain id
+func forkChainID(network string) flowgo.ChainID {
+	switch network {
+	case "mainnet":
+		return flowgo.Mainnet
+	case "testnet":
+		return flowgo.Testnet
+	case "previewnet":
+		return flowgo.Previewnet
+	default:
+		return flowgo.Emulator
+	}
+}
+
+// create the emulator options needed to run the embedded emulator on top of the given network
+func (o *OverflowBuilder) forkEmulatorOptions(network config.Network, logger *zerolog.Logger) ([]emulator.Option, error) {
+	baseStore, err := sqlite.New(sqlite.InMemory)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create local fork storage")
+	}
+
+	chainID := forkChainID(network.Name)
+	remoteOptions := []remote.Option{
+		remote.WithRPCHost(network.Host, chainID),
+		remote.WithStartBlockHeight(o.ForkHeight),
+	}
+	remoteOptions = append(remoteOptions, o.ForkOptions...)
+
+	store, err := remote.New(baseStore, logger, remoteOptions...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not fork network %s from host %s", network.Name, network.Host)
+	}
+
+	return []emulator.Option{
+		emulator.WithStore(store),
+		emulator.WithChainID(chainID),
+		// signatures and sequence numbers are not checked so that we can impersonate any account
+		emulator.WithTransactionValidationEnabled(false),
+	}, nil
+}
+
+// create an account that will sign as the given account name or address using the emulator service key
+// this only works if transaction validation is turned off, like it is in fork mode
+func (o *OverflowState) impersonatedAccount(nameOrAddress string) (*accounts.Account, error) {
+	var address flow.Address
+	account, err := o.AccountE(nameOrAddress)
+	if err == nil {
+		address = account.Address
+	} else {
+		cadenceAddress, err := hexToAddress(nameOrAddress)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not an valid account name or an address", nameOrAddress)
+		}
+		address = flow.Address(*cadenceAddress)
+	}
+
+	serviceAccount, err := o.State.EmulatorServiceAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	return &accounts.Account{
+		Name:    nameOrAddress,
+		Address: address,
+		Key:     serviceAccount.Key,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package overflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/storage"
+	"github.com/onflow/flow-emulator/storage/remote"
+	"github.com/onflow/flow-emulator/storage/sqlite"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	flowgo "github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/executiondata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// a local emulator storage that acts as the access node of the network we fork from
+type localAccessNode struct {
+	access.AccessAPIClient
+	store storage.Store
+	// blocks stored by the emulator do not have a chain id, so the id the fork sees is different
+	heights map[flowgo.Identifier]uint64
+}
+
+func (l *localAccessNode) GetNetworkParameters(_ context.Context, _ *access.GetNetworkParametersRequest, _ ...grpc.CallOption) (*access.GetNetworkParametersResponse, error) {
+	return &access.GetNetworkParametersResponse{ChainId: flowgo.Emulator.String()}, nil
+}
+
+func (l *localAccessNode) GetLatestBlockHeader(ctx context.Context, _ *access.GetLatestBlockHeaderRequest, _ ...grpc.CallOption) (*access.BlockHeaderResponse, error) {
+	block, err := l.store.LatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return l.blockHeaderResponse(&block)
+}
+
+func (l *localAccessNode) GetBlockHeaderByHeight(ctx context.Context, in *access.GetBlockHeaderByHeightRequest, _ ...grpc.CallOption) (*access.BlockHeaderResponse, error) {
+	block, err := l.store.BlockByHeight(ctx, in.Height)
+	if err != nil {
+		return nil, err
+	}
+	return l.blockHeaderResponse(block)
+}
+
+func (l *localAccessNode) GetBlockHeaderByID(ctx context.Context, in *access.GetBlockHeaderByIDRequest, _ ...grpc.CallOption) (*access.BlockHeaderResponse, error) {
+	height, ok := l.heights[flowgo.HashToID(in.Id)]
+	if !ok {
+		return nil, fmt.Errorf("block with id %x not found", in.Id)
+	}
+	block, err := l.store.BlockByHeight(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	return l.blockHeaderResponse(block)
+}
+
+func (l *localAccessNode) blockHeaderResponse(block *flowgo.Block) (*access.BlockHeaderResponse, error) {
+	h := *block.Header
+	h.ChainID = flowgo.Emulator
+	header, err := convert.BlockHeaderToMessage(&h, flowgo.IdentifierList{})
+	if err != nil {
+		return nil, err
+	}
+	l.heights[h.ID()] = h.Height
+	return &access.BlockHeaderResponse{Block: header}, nil
+}
+
+type localExecutionData struct {
+	executiondata.ExecutionDataAPIClient
+	store storage.Store
+}
+
+func (l *localExecutionData) GetRegisterValues(ctx context.Context, in *executiondata.GetRegisterValuesRequest, _ ...grpc.CallOption) (*executiondata.GetRegisterValuesResponse, error) {
+	ledger, err := l.store.LedgerByHeight(ctx, in.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := convert.MessagesToRegisterIDs(in.RegisterIds, flowgo.Emulator.Chain())
+	if err != nil {
+		return nil, err
+	}
+	values := [][]byte{}
+	for _, id := range ids {
+		value, err := ledger.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return &executiondata.GetRegisterValuesResponse{Values: values}, nil
+}
+
+func TestFork(t *testing.T) {
+	store, err := sqlite.New(sqlite.InMemory)
+	require.NoError(t, err)
+
+	remoteNetwork, err := OverflowTesting(WithEmulatorOption(emulator.WithStore(store)))
+	require.NoError(t, err)
+
+	balanceScript := `
+import "FungibleToken"
+import "FlowToken"
+
+access(all) fun main(account: Address): UFix64 {
+	return getAccount(account).capabilities.borrow<&FlowToken.Vault>(/public/flowTokenBalance)!.balance
+}`
+
+	remoteBalance := remoteNetwork.Script(balanceScript, WithArg("account", "first")).Output
+
+	block, err := remoteNetwork.GetLatestBlock(context.Background())
+	require.NoError(t, err)
+
+	fork := func(t *testing.T) *OverflowState {
+		o, err := OverflowTesting(
+			WithFork("emulator", block.Height),
+			WithForkOption(remote.WithClient(&localExecutionData{store: store}, &localAccessNode{store: store, heights: map[flowgo.Identifier]uint64{}})),
+		)
+		require.NoError(t, err)
+		return o
+	}
+
+	t.Run("Should start at the fork height", func(t *testing.T) {
+		o := fork(t)
+		forkBlock, err := o.GetLatestBlock(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, block.Height, forkBlock.Height)
+	})
+
+	t.Run("Should read state from the forked network using account names", func(t *testing.T) {
+		o := fork(t)
+		assert.Equal(t, "emulator", o.GetNetwork())
+		res := o.Script(balanceScript, WithArg("account", "first"))
+		require.NoError(t, res.Err)
+		assert.Equal(t, remoteBalance, res.Output)
+	})
+
+	t.Run("Should impersonate address and only change the fork", func(t *testing.T) {
+		o := fork(t)
+		o.Tx("sendFlow",
+			WithImpersonatedSigner(o.Address("first")),
+			WithArg("amount", 1.0),
+			WithArg("to", "second"),
+		).AssertSuccess(t).AssertEvent(t, "FungibleToken.Withdrawn", map[string]interface{}{
+			"amount": 1.0,
+			"from":   o.Address("first"),
+		})
+
+		assert.NotEqual(t, remoteBalance, o.Script(balanceScript, WithArg("account", "first")).Output)
+		assert.Equal(t, remoteBalance, remoteNetwork.Script(balanceScript, WithArg("account", "first")).Output)
+	})
+
+	t.Run("Should impersonate additional authorizers", func(t *testing.T) {
+		o := fork(t)
+		o.Tx(fmt.Sprintf(`
+transaction {
+	prepare(first: &Account, second: &Account) {
+		assert(first.address == %s, message: "wrong first authorizer")
+		assert(second.address == %s, message: "wrong second authorizer")
+	}
+}`, o.Address("second"), o.Address("first")),
+			WithImpersonatedAuthorizer(o.Address("second")),
+			WithImpersonatedSigner(o.Address("first")),
+		).AssertSuccess(t)
+	})
+
+	t.Run("Should fail impersonating invalid address", func(t *testing.T) {
+		o := fork(t)
+		o.Tx("sendFlow",
+			WithImpersonatedSigner("not-an-address"),
+			WithArg("amount", 1.0),
+			WithArg("to", "second"),
+		).AssertFailure(t, "not-an-address is not an valid account name or an address")
+	})
+}
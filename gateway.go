@@ -0,0 +1,483 @@
+package overflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"golang.org/x/exp/slices"
+)
+
+// HTTP gateway
+//
+// Serve exposes the scripts and transactions of the project on the current network as a local REST API for internal tools
+//
+//	GET  /scripts/{name}?account=first                           runs a script, the query parameters are the arguments
+//	POST /transactions/{name} {"signer": "first", "args": {...}}  sends a transaction signed by an account from flow.json
+//	GET  /openapi.json                                           the solution spec as an OpenAPI document
+//
+// Arguments are parsed like string values in WithArg, so account names can be used for addresses and any value can be sent as a cadence literal in a string.
+// A query parameter that is repeated is sent as an array.
+// Only interactions from the transaction and script folders are served, the responses are the json of an OverflowScriptResult or OverflowResult
+//
+// The gateway has no authentication, transactions can only be signed by the accounts given with WithGatewaySigners
+// and Serve only listens on a loopback address unless WithGatewayPublicAddress is used
+//
+//	o.Serve("localhost:8080", WithGatewaySigners("first", "second"))
+
+// a type representing setting an option in the gateway builder
+type OverflowGatewayOption func(*OverflowGatewayBuilder)
+
+// a type representing the accumulated state when creating a gateway
+type OverflowGatewayBuilder struct {
+	// the names of the accounts that can sign transactions, no transactions can be sent if it is empty
+	Signers []string
+
+	// allow Serve to listen on an address that is not a loopback address
+	PublicAddress bool
+}
+
+// allow transactions to be signed by the accounts with the given names
+func WithGatewaySigners(signers ...string) OverflowGatewayOption {
+	return func(ogb *OverflowGatewayBuilder) {
+		ogb.Signers = append(ogb.Signers, signers...)
+	}
+}
+
+// allow Serve to listen on other addresses than loopback addresses, everybody that can reach it can sign with the allowed signers
+func WithGatewayPublicAddress() OverflowGatewayOption {
+	return func(ogb *OverflowGatewayBuilder) {
+		ogb.PublicAddress = true
+	}
+}
+
+func gatewayBuilder(opts []OverflowGatewayOption) *OverflowGatewayBuilder {
+	builder := &OverflowGatewayBuilder{}
+	for _, opt := range opts {
+		opt(builder)
+	}
+	return builder
+}
+
+// the time a client has to send the headers of a request
+const gatewayReadHeaderTimeout = 10 * time.Second
+
+// the body of a transaction request
+type gatewayTransactionRequest struct {
+	Signer string                     `json:"signer"`
+	Args   map[string]json.RawMessage `json:"args"`
+}
+
+// the json of a failed request
+type gatewayError struct {
+	Err string `json:"error"`
+}
+
+type overflowGateway struct {
+	o        *OverflowState
+	solution OverflowSolutionMergedNetwork
+	openAPI  []byte
+	signers  []string
+}
+
+// GatewayHandler creates the http handler used by Serve, the interactions are read once when it is created
+func (o *OverflowState) GatewayHandler(opts ...OverflowGatewayOption) (http.Handler, error) {
+	solution, err := o.ParseAll()
+	if err != nil {
+		return nil, err
+	}
+	network, ok := solution.MergeSpecAndCode().Networks[o.Network.Name]
+	if !ok {
+		return nil, fmt.Errorf("could not find network %s in the solution", o.Network.Name)
+	}
+	openAPI, err := json.MarshalIndent(gatewayOpenAPI(o.Network.Name, network), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gateway := &overflowGateway{o: o, solution: network, openAPI: openAPI, signers: gatewayBuilder(opts).Signers}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scripts/", gateway.script)
+	mux.HandleFunc("/transactions/", gateway.transaction)
+	mux.HandleFunc("/openapi.json", gateway.spec)
+	return mux, nil
+}
+
+// Serve exposes the scripts and transactions of the project as a REST API on the given address, it blocks until the server stops
+func (o *OverflowState) Serve(addr string, opts ...OverflowGatewayOption) error {
+	if !gatewayBuilder(opts).PublicAddress && !loopbackAddress(addr) {
+		return fmt.Errorf("the gateway can sign transactions and only listens on a loopback address like localhost:8080, use WithGatewayPublicAddress to serve on %s", addr)
+	}
+	handler, err := o.GatewayHandler(opts...)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: gatewayReadHeaderTimeout,
+	}
+	return server.ListenAndServe()
+}
+
+// an address with a host that only resolves to loopback addresses, an empty host listens on all interfaces
+func loopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeGatewayJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeGatewayError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeGatewayJSON(w, status, gatewayError{Err: fmt.Sprintf(format, args...)})
+}
+
+// an argument from a json body as a value for WithArg, everything but strings and null is sent as the json text that is parsed as a cadence literal
+func gatewayArgument(raw json.RawMessage) (interface{}, error) {
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(trimmed, []byte("null")):
+		return nil, nil
+	case bytes.HasPrefix(trimmed, []byte(`"`)):
+		var value string
+		err := json.Unmarshal(trimmed, &value)
+		return value, err
+	}
+	var compact bytes.Buffer
+	err := json.Compact(&compact, trimmed)
+	return compact.String(), err
+}
+
+// javascript sends whole numbers without a decimal point, a fixed point literal needs one
+func gatewayFixedPoint(spec *OverflowDeclarationInfo, parameter string, value interface{}) interface{} {
+	literal, ok := value.(string)
+	if !ok || spec == nil || (spec.Parameters[parameter] != "UFix64" && spec.Parameters[parameter] != "Fix64") {
+		return value
+	}
+	if _, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return literal + ".0"
+	}
+	return value
+}
+
+func (g *overflowGateway) script(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeGatewayError(w, http.StatusMethodNotAllowed, "scripts are run with GET")
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/scripts/")
+	script, ok := g.solution.Scripts[name]
+	if !ok {
+		writeGatewayError(w, http.StatusNotFound, "could not find script %s", name)
+		return
+	}
+
+	args := map[string]interface{}{}
+	for parameter, values := range r.URL.Query() {
+		if len(values) == 1 {
+			args[parameter] = gatewayFixedPoint(script.Spec, parameter, values[0])
+			continue
+		}
+		value, err := g.queryArray(script.Spec, parameter, values)
+		if err != nil {
+			writeGatewayError(w, http.StatusBadRequest, "could not read argument %s: %v", parameter, err)
+			return
+		}
+		args[parameter] = value
+	}
+
+	// the interaction is run without Script so that StopOnError does not stop the server
+	interaction := g.o.BuildInteraction(name, "script", WithArgsMap(args), WithContext(r.Context()), WithoutLog())
+	result := interaction.runScript()
+	if result.Err != nil {
+		writeGatewayJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+	writeGatewayJSON(w, http.StatusOK, result)
+}
+
+// the values of a repeated query parameter as a cadence array literal, elements are parsed like a single value of their type
+func (g *overflowGateway) queryArray(spec *OverflowDeclarationInfo, parameter string, values []string) (string, error) {
+	var elementType ast.Type
+	if spec != nil {
+		switch t := parseCadenceType(spec.Parameters[parameter]).(type) {
+		case *ast.VariableSizedType:
+			elementType = t.Type
+		case *ast.ConstantSizedType:
+			elementType = t.Type
+		}
+	}
+	if elementType == nil {
+		return "", fmt.Errorf("it is given %d times but is not an array", len(values))
+	}
+
+	elementName := ""
+	if nominal, ok := elementType.(*ast.NominalType); ok && len(nominal.NestedIdentifiers) == 0 {
+		elementName = nominal.Identifier.Identifier
+	}
+	elements := make([]string, len(values))
+	for i, value := range values {
+		switch elementName {
+		case "String":
+			value = strconv.Quote(value)
+		case "Address":
+			if account, err := g.o.AccountE(value); err == nil {
+				value = account.Address.String()
+			}
+			if !strings.HasPrefix(value, "0x") {
+				value = "0x" + value
+			}
+		case "UFix64", "Fix64":
+			if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+				value = value + ".0"
+			}
+		}
+		elements[i] = value
+	}
+	return "[" + strings.Join(elements, ", ") + "]", nil
+}
+
+func (g *overflowGateway) transaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeGatewayError(w, http.StatusMethodNotAllowed, "transactions are sent with POST")
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/transactions/")
+	transaction, ok := g.solution.Transactions[name]
+	if !ok {
+		writeGatewayError(w, http.StatusNotFound, "could not find transaction %s", name)
+		return
+	}
+
+	request := gatewayTransactionRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeGatewayError(w, http.StatusBadRequest, "could not read the body of transaction %s: %v", name, err)
+		return
+	}
+	if request.Signer == "" {
+		writeGatewayError(w, http.StatusBadRequest, "transaction %s needs a signer", name)
+		return
+	}
+	if !slices.Contains(g.signers, request.Signer) {
+		writeGatewayError(w, http.StatusForbidden, "signer %s is not allowed to sign transactions in the gateway", request.Signer)
+		return
+	}
+	args := map[string]interface{}{}
+	for parameter, raw := range request.Args {
+		value, err := gatewayArgument(raw)
+		if err != nil {
+			writeGatewayError(w, http.StatusBadRequest, "could not read argument %s: %v", parameter, err)
+			return
+		}
+		args[parameter] = gatewayFixedPoint(transaction.Spec, parameter, value)
+	}
+
+	// the interaction is sent without Tx so that StopOnError does not stop the server
+	interaction := g.o.BuildInteraction(name, "transaction",
+		WithSigner(request.Signer),
+		WithArgsMap(args),
+		WithContext(r.Context()),
+		WithPanicInteractionOnError(false),
+		WithoutLog(),
+	)
+	result := interaction.Send()
+	if result.Err != nil {
+		writeGatewayJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+	writeGatewayJSON(w, http.StatusOK, result)
+}
+
+func (g *overflowGateway) spec(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(g.openAPI)
+}
+
+// the json schema of a cadence type, values that can only be sent as a cadence literal are strings
+func gatewaySchema(cadenceType ast.Type) map[string]interface{} {
+	switch t := cadenceType.(type) {
+	case *ast.NominalType:
+		name := t.Identifier.Identifier
+		switch {
+		case len(t.NestedIdentifiers) > 0:
+		case typeScriptIntegerTypes[name]:
+			return map[string]interface{}{"type": "integer"}
+		case name == "Fix64" || name == "UFix64":
+			return map[string]interface{}{"type": "number"}
+		case name == "Bool":
+			return map[string]interface{}{"type": "boolean"}
+		}
+	case *ast.OptionalType:
+		schema := gatewaySchema(t.Type)
+		schema["nullable"] = true
+		return schema
+	case *ast.VariableSizedType:
+		return map[string]interface{}{"type": "array", "items": gatewaySchema(t.Type)}
+	case *ast.ConstantSizedType:
+		return map[string]interface{}{"type": "array", "items": gatewaySchema(t.Type)}
+	case *ast.DictionaryType:
+		return map[string]interface{}{"type": "object", "additionalProperties": gatewaySchema(t.ValueType)}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// the schema of a parameter with its cadence type and the description from the doc comment
+func gatewayParameterSchema(spec *OverflowDeclarationInfo, parameter string) map[string]interface{} {
+	cadenceType := spec.Parameters[parameter]
+	schema := map[string]interface{}{"type": "string"}
+	if parsed := parseCadenceType(cadenceType); parsed != nil {
+		schema = gatewaySchema(parsed)
+	}
+	schema["x-cadence-type"] = cadenceType
+	if spec.Doc != nil && spec.Doc.Parameters[parameter] != "" {
+		schema["description"] = spec.Doc.Parameters[parameter]
+	}
+	return schema
+}
+
+// the summary, description and deprecation of an operation from the doc comment
+func gatewayOperation(operationId string, spec *OverflowDeclarationInfo) map[string]interface{} {
+	operation := map[string]interface{}{"operationId": operationId}
+	if spec == nil || spec.Doc == nil {
+		return operation
+	}
+	if spec.Doc.Title != "" {
+		operation["summary"] = spec.Doc.Title
+	}
+	if spec.Doc.Description != "" {
+		operation["description"] = spec.Doc.Description
+	}
+	if _, ok := spec.Doc.Tags["deprecated"]; ok {
+		operation["deprecated"] = true
+	}
+	return operation
+}
+
+func gatewayResponses(result string) map[string]interface{} {
+	response := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/" + result}},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"200": response("the result"),
+		"422": response("the result with the error of the interaction"),
+	}
+}
+
+// an OpenAPI document for the gateway of the interactions on a network
+func gatewayOpenAPI(networkName string, network OverflowSolutionMergedNetwork) map[string]interface{} {
+	paths := map[string]interface{}{}
+	for _, name := range sortedInteractionNames(network.Scripts) {
+		spec := network.Scripts[name].Spec
+		operation := gatewayOperation("script "+name, spec)
+		parameters := []interface{}{}
+		if spec != nil {
+			for _, parameter := range spec.ParameterOrder {
+				parameters = append(parameters, map[string]interface{}{
+					"name":     parameter,
+					"in":       "query",
+					"required": true,
+					"schema":   gatewayParameterSchema(spec, parameter),
+				})
+			}
+		}
+		operation["parameters"] = parameters
+		operation["responses"] = gatewayResponses("ScriptResult")
+		paths["/scripts/"+name] = map[string]interface{}{"get": operation}
+	}
+
+	for _, name := range sortedInteractionNames(network.Transactions) {
+		spec := network.Transactions[name].Spec
+		operation := gatewayOperation("transaction "+name, spec)
+		properties := map[string]interface{}{}
+		required := []string{}
+		if spec != nil {
+			for _, parameter := range spec.ParameterOrder {
+				properties[parameter] = gatewayParameterSchema(spec, parameter)
+				required = append(required, parameter)
+			}
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{
+						"type":     "object",
+						"required": []string{"signer", "args"},
+						"properties": map[string]interface{}{
+							"signer": map[string]interface{}{"type": "string", "description": "the name of an account in flow.json"},
+							"args":   map[string]interface{}{"type": "object", "required": required, "properties": properties},
+						},
+					},
+				},
+			},
+		}
+		operation["responses"] = gatewayResponses("TransactionResult")
+		paths["/transactions/"+name] = map[string]interface{}{"post": operation}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   fmt.Sprintf("overflow gateway for %s", networkName),
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"ScriptResult": map[string]interface{}{
+					"type":        "object",
+					"description": "an OverflowScriptResult",
+					"properties": map[string]interface{}{
+						"Err":    map[string]interface{}{"type": "string"},
+						"Result": map[string]interface{}{"description": "the cadence value"},
+						"Output": map[string]interface{}{"description": "the cadence value as json"},
+						"Log":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+					},
+				},
+				"TransactionResult": map[string]interface{}{
+					"type":        "object",
+					"description": "an OverflowResult",
+					"properties": map[string]interface{}{
+						"Id":                map[string]interface{}{"type": "string"},
+						"Name":              map[string]interface{}{"type": "string"},
+						"Err":               map[string]interface{}{"type": "string"},
+						"Arguments":         map[string]interface{}{"type": "object"},
+						"Events":            map[string]interface{}{"type": "object"},
+						"RawEvents":         map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+						"ComputationUsed":   map[string]interface{}{"type": "integer"},
+						"Fee":               map[string]interface{}{"type": "object"},
+						"FeeGas":            map[string]interface{}{"type": "integer"},
+						"EmulatorLog":       map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						"Transaction":       map[string]interface{}{"type": "object"},
+						"TransactionResult": map[string]interface{}{"type": "object"},
+						"DeclarationInfo":   map[string]interface{}{"type": "object"},
+						"StorageUsage":      map[string]interface{}{"type": "object"},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,195 @@
+package overflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateway(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	handler, err := o.GatewayHandler(WithGatewaySigners("account", "first"))
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(t *testing.T, path string) (int, map[string]interface{}) {
+		response, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+		return response.StatusCode, body
+	}
+
+	post := func(t *testing.T, path string, request string) (int, map[string]interface{}) {
+		response, err := http.Post(server.URL+path, "application/json", strings.NewReader(request))
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+		return response.StatusCode, body
+	}
+
+	t.Run("run script", func(t *testing.T) {
+		status, body := get(t, "/scripts/aScript?account=first")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "0x179b6b1cb6755e31", body["Output"])
+		assert.NotContains(t, body, "Err")
+		assert.NotContains(t, body, "Input")
+	})
+
+	t.Run("run script with the network prefix", func(t *testing.T) {
+		status, body := get(t, "/scripts/Foo?account=first")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "0x179b6b1cb6755e31", body["Output"])
+	})
+
+	t.Run("script with a missing argument", func(t *testing.T) {
+		status, body := get(t, "/scripts/aScript")
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body["Err"], "the interaction 'aScript' is missing [account]")
+	})
+
+	t.Run("repeated argument that is not an array", func(t *testing.T) {
+		status, body := get(t, "/scripts/aScript?account=first&account=second")
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "could not read argument account: it is given 2 times but is not an array", body["error"])
+	})
+
+	t.Run("repeated arguments are an array", func(t *testing.T) {
+		gateway := &overflowGateway{o: o}
+		spec := &OverflowDeclarationInfo{Parameters: map[string]string{
+			"names":    "[String]",
+			"accounts": "[Address]",
+			"amounts":  "[UFix64; 2]",
+			"ids":      "[UInt64]",
+		}}
+		inputs := map[string][]string{
+			"names":    {"foo", `b"ar`},
+			"accounts": {"first", "0x01cf0e2f2f715450"},
+			"amounts":  {"10", "0.5"},
+			"ids":      {"1", "2"},
+		}
+		expected := map[string]string{
+			"names":    `["foo", "b\"ar"]`,
+			"accounts": "[0x179b6b1cb6755e31, 0x01cf0e2f2f715450]",
+			"amounts":  "[10.0, 0.5]",
+			"ids":      "[1, 2]",
+		}
+		for parameter, values := range inputs {
+			value, err := gateway.queryArray(spec, parameter, values)
+			require.NoError(t, err)
+			assert.Equal(t, expected[parameter], value, parameter)
+		}
+	})
+
+	t.Run("unknown script", func(t *testing.T) {
+		status, body := get(t, "/scripts/"+"access(all)%20fun%20main()%20{}")
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Contains(t, body["error"], "could not find script")
+	})
+
+	t.Run("send transaction", func(t *testing.T) {
+		status, body := post(t, "/transactions/mint_tokens", `{"signer": "account", "args": {"recipient": "first", "amount": 10}}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "mint_tokens", body["Name"])
+		assert.NotEmpty(t, body["Id"])
+		assert.Contains(t, body["Arguments"], "recipient")
+		assert.Contains(t, body["Arguments"], "amount")
+		assert.Contains(t, body["Events"], "A.0ae53cb6e3f42a79.FlowToken.TokensMinted")
+		assert.NotContains(t, body, "Err")
+	})
+
+	t.Run("send transaction with a string argument", func(t *testing.T) {
+		status, body := post(t, "/transactions/arguments", `{"signer": "first", "args": {"test": "foo"}}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "arguments", body["Name"])
+		assert.Contains(t, body["Arguments"], "test")
+	})
+
+	t.Run("failing transaction", func(t *testing.T) {
+		status, body := post(t, "/transactions/mint_tokens", `{"signer": "first", "args": {"recipient": "first", "amount": 10.0}}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body["Err"], "Signer is not the token admin")
+	})
+
+	t.Run("transaction without signer", func(t *testing.T) {
+		status, body := post(t, "/transactions/arguments", `{"args": {"test": "foo"}}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "transaction arguments needs a signer", body["error"])
+	})
+
+	t.Run("signer that is not allowed", func(t *testing.T) {
+		status, body := post(t, "/transactions/arguments", `{"signer": "second", "args": {"test": "foo"}}`)
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, "signer second is not allowed to sign transactions in the gateway", body["error"])
+	})
+
+	t.Run("wrong method", func(t *testing.T) {
+		status, _ := get(t, "/transactions/arguments")
+		assert.Equal(t, http.StatusMethodNotAllowed, status)
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		status, body := get(t, "/openapi.json")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "3.0.3", body["openapi"])
+		paths := body["paths"].(map[string]interface{})
+		assert.Contains(t, paths, "/scripts/aScript")
+		assert.Contains(t, paths, "/transactions/mint_tokens")
+
+		mint := paths["/transactions/mint_tokens"].(map[string]interface{})["post"].(map[string]interface{})
+		schema := mint["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+		args := schema["properties"].(map[string]interface{})["args"].(map[string]interface{})
+		assert.Equal(t, []interface{}{"recipient", "amount"}, args["required"])
+		assert.Equal(t, map[string]interface{}{"type": "number", "x-cadence-type": "UFix64"}, args["properties"].(map[string]interface{})["amount"])
+	})
+}
+
+func TestGatewayArgument(t *testing.T) {
+	inputs := map[string]interface{}{
+		`"first"`:   "first",
+		`10`:        "10",
+		`10.5`:      "10.5",
+		`true`:      "true",
+		`null`:      nil,
+		`[1, 2, 3]`: "[1,2,3]",
+		`{"a": 1}`:  `{"a":1}`,
+	}
+	for raw, expected := range inputs {
+		value, err := gatewayArgument(json.RawMessage(raw))
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, raw)
+	}
+
+	spec := &OverflowDeclarationInfo{Parameters: map[string]string{"amount": "UFix64", "count": "Int"}}
+	assert.Equal(t, "10.0", gatewayFixedPoint(spec, "amount", "10"))
+	assert.Equal(t, "10.5", gatewayFixedPoint(spec, "amount", "10.5"))
+	assert.Equal(t, "10", gatewayFixedPoint(spec, "count", "10"))
+	assert.Nil(t, gatewayFixedPoint(spec, "amount", nil))
+}
+
+func TestGatewayServe(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	t.Run("public address is refused", func(t *testing.T) {
+		for _, addr := range []string{":8080", "0.0.0.0:8080", "192.168.1.10:8080", "example.com:8080", "8080"} {
+			assert.ErrorContains(t, o.Serve(addr), "use WithGatewayPublicAddress to serve on "+addr)
+		}
+	})
+
+	t.Run("loopback address", func(t *testing.T) {
+		for _, addr := range []string{"localhost:8080", "127.0.0.1:8080", "[::1]:8080"} {
+			assert.True(t, loopbackAddress(addr), addr)
+		}
+	})
+}
@@ -23,7 +23,7 @@ func (o *OverflowState) GenerateStub(network, filePath string, standalone bool)
 		commandName = "Script"
 	}
 	if interaction == nil {
-		return "", fmt.Errorf("could not find interaction of type %s with name %s", commandName, interaction)
+		return "", fmt.Errorf("could not find interaction of type %s with name %s", commandName, interactionName)
 	}
 	lines := []string{
 		fmt.Sprintf(`  o.%s("%s",`, commandName, interactionName),
@@ -0,0 +1,415 @@
+package overflow
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/pkg/errors"
+)
+
+// Typed client
+//
+// GenerateClient creates a go package with one function for every transaction and script in the project, so that wrong argument names or
+// a wrong number of signers is a compile error. Run it from a small program with go:generate
+//
+//	//go:generate go run ./cmd/generate
+//
+//	func main() {
+//		o := overflow.Overflow(overflow.WithNetwork("embedded"))
+//		err := o.GenerateClientFile("client", "client/client_gen.go")
+//		...
+//	}
+//
+//	c := client.New(o)
+//	c.Transactions.MintTokens("alice", "bob", 10.0)
+//	balance, err := c.Scripts.GetBalance("bob")
+
+// go types for cadence types that are converted correctly by WithArg when nested in arrays, dictionaries and optionals
+var goInputTypes = map[string]string{
+	"String": "string",
+	"Bool":   "bool",
+	"Int":    "int",
+	"Int8":   "int8",
+	"Int16":  "int16",
+	"Int32":  "int32",
+	"Int64":  "int64",
+	"UInt":   "uint",
+	"UInt8":  "uint8",
+	"UInt16": "uint16",
+	"UInt32": "uint32",
+	"UInt64": "uint64",
+	"UFix64": "float64",
+}
+
+// cadence types that are sent as strings and parsed as literals when they are not nested
+var goLiteralInputTypes = map[string]bool{
+	"Address": true, "Fix64": true,
+	"Int128": true, "Int256": true, "UInt128": true, "UInt256": true,
+	"Word8": true, "Word16": true, "Word32": true, "Word64": true, "Word128": true, "Word256": true,
+	"Path": true, "StoragePath": true, "PublicPath": true, "PrivatePath": true, "CapabilityPath": true,
+}
+
+// go types for the json output of cadence types in a script result
+var goOutputTypes = map[string]string{
+	"String": "string", "Character": "string", "Address": "string",
+	"Int": "string", "UInt": "string",
+	"Int128": "string", "Int256": "string", "UInt128": "string", "UInt256": "string", "Word128": "string", "Word256": "string",
+	"Path": "string", "StoragePath": "string", "PublicPath": "string", "PrivatePath": "string", "CapabilityPath": "string",
+	"Bool":  "bool",
+	"Int8":  "int8",
+	"Int16": "int16",
+	"Int32": "int32",
+	"Int64": "int64",
+	"UInt8": "uint8", "Word8": "uint8",
+	"UInt16": "uint16", "Word16": "uint16",
+	"UInt32": "uint32", "Word32": "uint32",
+	"UInt64": "uint64", "Word64": "uint64",
+	"UFix64": "float64", "Fix64": "float64",
+}
+
+// the go type of an argument, cadence.Value if there is no go type that is converted to the right cadence type
+func goInputType(cadenceType ast.Type, nested bool) string {
+	switch t := cadenceType.(type) {
+	case *ast.NominalType:
+		if len(t.NestedIdentifiers) > 0 {
+			return "cadence.Value"
+		}
+		if goType, ok := goInputTypes[t.Identifier.Identifier]; ok {
+			return goType
+		}
+		if !nested && goLiteralInputTypes[t.Identifier.Identifier] {
+			return "string"
+		}
+	case *ast.OptionalType:
+		if inner := goInputType(t.Type, true); inner != "cadence.Value" {
+			return "*" + inner
+		}
+	case *ast.VariableSizedType:
+		if inner := goInputType(t.Type, true); inner != "cadence.Value" {
+			return "[]" + inner
+		}
+	case *ast.DictionaryType:
+		key := goInputType(t.KeyType, true)
+		value := goInputType(t.ValueType, true)
+		if key != "cadence.Value" && value != "cadence.Value" {
+			return fmt.Sprintf("map[%s]%s", key, value)
+		}
+	}
+	return "cadence.Value"
+}
+
+// the go type the output of a script is unmarshalled into, interface{} for composites
+func goOutputType(cadenceType ast.Type) string {
+	switch t := cadenceType.(type) {
+	case *ast.NominalType:
+		if len(t.NestedIdentifiers) == 0 {
+			if goType, ok := goOutputTypes[t.Identifier.Identifier]; ok {
+				return goType
+			}
+		}
+	case *ast.OptionalType:
+		inner := goOutputType(t.Type)
+		if inner == "interface{}" {
+			return inner
+		}
+		return "*" + inner
+	case *ast.VariableSizedType:
+		return "[]" + goOutputType(t.Type)
+	case *ast.ConstantSizedType:
+		return "[]" + goOutputType(t.Type)
+	case *ast.DictionaryType:
+		// json object keys can only be unmarshalled into strings and integers
+		key := goOutputType(t.KeyType)
+		if key == "bool" || key == "float64" || key == "interface{}" || strings.HasPrefix(key, "*") {
+			key = "string"
+		}
+		return fmt.Sprintf("map[%s]%s", key, goOutputType(t.ValueType))
+	}
+	return "interface{}"
+}
+
+// a type written as a string in a declaration info, nil if it can not be parsed
+func parseCadenceType(cadenceType string) ast.Type {
+	parsed, errs := parser.ParseType(nil, []byte(cadenceType), parser.Config{})
+	if len(errs) > 0 {
+		return nil
+	}
+	return parsed
+}
+
+// an exported go identifier for the name of an interaction, mint_tokens is MintTokens
+func goExportedName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	result := sb.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "X" + result
+	}
+	return result
+}
+
+// names used in the generated functions that parameters can not have
+var goReservedNames = map[string]bool{
+	"c": true, "opts": true, "value": true, "err": true, "overflow": true, "cadence": true, "proposer": true,
+}
+
+// the name of a parameter in the generated function
+func goParameterName(name string, signers []string) string {
+	reserved := goReservedNames[name] || token.IsKeyword(name)
+	for _, signer := range signers {
+		reserved = reserved || signer == name
+	}
+	if reserved {
+		return name + "Arg"
+	}
+	return name
+}
+
+// the names of the signer parameters for a transaction with the given number of authorizers
+func goSignerNames(authorizers int) []string {
+	if authorizers == 0 {
+		return []string{"proposer"}
+	}
+	if authorizers == 1 {
+		return []string{"signer"}
+	}
+	signers := []string{}
+	for i := 1; i <= authorizers; i++ {
+		signers = append(signers, fmt.Sprintf("signer%d", i))
+	}
+	return signers
+}
+
+// the parameters of a generated function and the options that send them as arguments
+func goArguments(spec *OverflowDeclarationInfo, signers []string) ([]string, []string, bool) {
+	parameters := []string{}
+	options := []string{}
+	usesCadence := false
+	for _, name := range spec.ParameterOrder {
+		goType := goInputType(parseCadenceType(spec.Parameters[name]), false)
+		usesCadence = usesCadence || strings.Contains(goType, "cadence.")
+		parameter := goParameterName(name, signers)
+		parameters = append(parameters, fmt.Sprintf("%s %s", parameter, goType))
+		options = append(options, fmt.Sprintf("overflow.WithArg(%q, %s),", name, parameter))
+	}
+	return parameters, options, usesCadence
+}
+
+// the lines of a go doc comment after the first line for the doc comment of an interaction, a deprecated tag is a Deprecated paragraph
+func goDocComment(spec *OverflowDeclarationInfo) string {
+	doc := spec.Doc
+	if doc == nil {
+		return ""
+	}
+	// the title and description continue the first paragraph so that gofmt does not turn a short line into a heading
+	paragraphs := []string{}
+	summary := strings.TrimSpace(doc.Title + "\n" + doc.Description)
+	if summary != "" {
+		paragraphs = append(paragraphs, summary)
+	}
+	parameters := []string{}
+	for _, parameter := range spec.ParameterOrder {
+		if description, ok := doc.Parameters[parameter]; ok {
+			parameters = append(parameters, fmt.Sprintf("%s: %s", parameter, description))
+		}
+	}
+	if len(parameters) > 0 {
+		paragraphs = append(paragraphs, strings.Join(parameters, "\n"))
+	}
+	tags := []string{}
+	for tag, text := range doc.Tags {
+		if tag == "deprecated" {
+			continue
+		}
+		tags = append(tags, strings.TrimSpace(fmt.Sprintf("@%s %s", tag, text)))
+	}
+	sort.Strings(tags)
+	if len(tags) > 0 {
+		paragraphs = append(paragraphs, strings.Join(tags, "\n"))
+	}
+	if deprecated, ok := doc.Tags["deprecated"]; ok {
+		paragraphs = append(paragraphs, strings.TrimSpace("Deprecated: "+deprecated))
+	}
+
+	var sb strings.Builder
+	for index, paragraph := range paragraphs {
+		if index > 0 || summary == "" {
+			fmt.Fprintln(&sb, "//")
+		}
+		for _, line := range strings.Split(paragraph, "\n") {
+			fmt.Fprintln(&sb, strings.TrimRight("// "+line, " "))
+		}
+	}
+	return sb.String()
+}
+
+func sortedDeclarationNames(declarations map[string]*OverflowDeclarationInfo) []string {
+	names := []string{}
+	for name := range declarations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GenerateClient creates the source of a go package with a typed function for every transaction and script in the project
+// Transactions take one signer for each authorizer in prepare, the last one also pays, and a proposer if there are no authorizers
+func (o *OverflowState) GenerateClient(packageName string) (string, error) {
+	solution, err := o.ParseAll()
+	if err != nil {
+		return "", err
+	}
+
+	var body strings.Builder
+	usesCadence := false
+	functionNames := map[string]string{}
+	checkName := func(kind string, name string) (string, error) {
+		functionName := goExportedName(name)
+		key := kind + functionName
+		if other, ok := functionNames[key]; ok {
+			return "", fmt.Errorf("the %ss %s and %s both generate the function %s", kind, other, name, functionName)
+		}
+		functionNames[key] = name
+		return functionName, nil
+	}
+
+	for _, name := range sortedDeclarationNames(solution.Transactions) {
+		spec := solution.Transactions[name]
+		functionName, err := checkName("transaction", name)
+		if err != nil {
+			return "", err
+		}
+
+		signers := goSignerNames(len(spec.Authorizers))
+		parameters := []string{}
+		for _, signer := range signers {
+			parameters = append(parameters, signer+" string")
+		}
+		arguments, options, cadenceArguments := goArguments(spec, signers)
+		usesCadence = usesCadence || cadenceArguments
+		parameters = append(parameters, arguments...)
+		parameters = append(parameters, "opts ...overflow.OverflowInteractionOption")
+
+		// authorizers are the payload signers followed by the payer
+		signerOptions := []string{}
+		switch len(spec.Authorizers) {
+		case 0:
+			signerOptions = append(signerOptions, "overflow.WithProposer(proposer),")
+		case 1:
+			signerOptions = append(signerOptions, "overflow.WithSigner(signer),")
+		default:
+			signerOptions = append(signerOptions, fmt.Sprintf("overflow.WithPayloadSigner(%s),", strings.Join(signers[:len(signers)-1], ", ")))
+			signerOptions = append(signerOptions, fmt.Sprintf("overflow.WithSigner(%s),", signers[len(signers)-1]))
+		}
+
+		fmt.Fprintf(&body, "\n// %s sends the transaction %s\n", functionName, name)
+		body.WriteString(goDocComment(spec))
+		fmt.Fprintf(&body, "func (c Transactions) %s(%s) *overflow.OverflowResult {\n", functionName, strings.Join(parameters, ", "))
+		fmt.Fprintf(&body, "return c.o.Tx(%q, append([]overflow.OverflowInteractionOption{\n", name)
+		fmt.Fprintf(&body, "%s\n", strings.Join(append(signerOptions, options...), "\n"))
+		fmt.Fprintln(&body, "}, opts...)...)")
+		fmt.Fprintln(&body, "}")
+	}
+
+	for _, name := range sortedDeclarationNames(solution.Scripts) {
+		spec := solution.Scripts[name]
+		functionName, err := checkName("script", name)
+		if err != nil {
+			return "", err
+		}
+
+		arguments, options, cadenceArguments := goArguments(spec, nil)
+		usesCadence = usesCadence || cadenceArguments
+		parameters := append(arguments, "opts ...overflow.OverflowInteractionOption")
+
+		call := fmt.Sprintf("c.o.Script(%q, append([]overflow.OverflowInteractionOption{\n%s\n}, opts...)...)", name, strings.Join(options, "\n"))
+		if len(options) == 0 {
+			call = fmt.Sprintf("c.o.Script(%q, opts...)", name)
+		}
+
+		fmt.Fprintf(&body, "\n// %s runs the script %s\n", functionName, name)
+		body.WriteString(goDocComment(spec))
+		if spec.ReturnType == "" {
+			fmt.Fprintf(&body, "func (c Scripts) %s(%s) error {\n", functionName, strings.Join(parameters, ", "))
+			fmt.Fprintf(&body, "return %s.Err\n", call)
+			fmt.Fprintln(&body, "}")
+			continue
+		}
+		outputType := goOutputType(parseCadenceType(spec.ReturnType))
+		fmt.Fprintf(&body, "func (c Scripts) %s(%s) (%s, error) {\n", functionName, strings.Join(parameters, ", "), outputType)
+		fmt.Fprintf(&body, "var value %s\n", outputType)
+		fmt.Fprintf(&body, "err := %s.MarshalAs(&value)\n", call)
+		fmt.Fprintln(&body, "return value, err")
+		fmt.Fprintln(&body, "}")
+	}
+
+	imports := []string{`"github.com/bjartek/overflow/v2"`}
+	if usesCadence {
+		imports = append(imports, `"github.com/onflow/cadence"`)
+	}
+
+	var source strings.Builder
+	fmt.Fprintln(&source, "// Code generated by overflow. DO NOT EDIT.")
+	fmt.Fprintln(&source)
+	fmt.Fprintf(&source, "package %s\n\n", packageName)
+	fmt.Fprintf(&source, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	fmt.Fprintln(&source, "// Client has a typed function for every transaction and script")
+	fmt.Fprintln(&source, "type Client struct {")
+	fmt.Fprintln(&source, "Transactions Transactions")
+	fmt.Fprintln(&source, "Scripts Scripts")
+	fmt.Fprintln(&source, "}")
+	fmt.Fprintln(&source)
+	fmt.Fprintln(&source, "// Transactions has a function for every transaction")
+	fmt.Fprintln(&source, "type Transactions struct {")
+	fmt.Fprintln(&source, "o overflow.OverflowClient")
+	fmt.Fprintln(&source, "}")
+	fmt.Fprintln(&source)
+	fmt.Fprintln(&source, "// Scripts has a function for every script")
+	fmt.Fprintln(&source, "type Scripts struct {")
+	fmt.Fprintln(&source, "o overflow.OverflowClient")
+	fmt.Fprintln(&source, "}")
+	fmt.Fprintln(&source)
+	fmt.Fprintln(&source, "// New creates a client that sends interactions with the given overflow client")
+	fmt.Fprintln(&source, "func New(o overflow.OverflowClient) *Client {")
+	fmt.Fprintln(&source, "return &Client{Transactions: Transactions{o: o}, Scripts: Scripts{o: o}}")
+	fmt.Fprintln(&source, "}")
+	source.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return "", errors.Wrap(err, "could not format generated client")
+	}
+	return string(formatted), nil
+}
+
+// GenerateClientFile writes the client generated with GenerateClient to the given file
+func (o *OverflowState) GenerateClientFile(packageName string, file string) error {
+	source, err := o.GenerateClient(packageName)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(source), 0o644)
+}
@@ -0,0 +1,68 @@
+package overflow
+
+import (
+	"testing"
+
+	"github.com/hexops/autogold"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateClient(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	t.Run("client", func(t *testing.T) {
+		source, err := o.GenerateClient("client")
+		require.NoError(t, err)
+		autogold.Equal(t, autogold.Raw(source))
+	})
+
+	t.Run("doc comments", func(t *testing.T) {
+		nested, err := OverflowTesting(
+			WithTransactionFolderName("testdata/nested/transactions"),
+			WithScriptFolderName("testdata/nested/scripts"),
+		)
+		require.NoError(t, err)
+		source, err := nested.GenerateClient("client")
+		require.NoError(t, err)
+		assert.Contains(t, source, `// AdminMint sends the transaction admin/mint
+// Mint flow tokens
+// Mints new tokens with the token admin of the signer
+//
+// recipient: the account that receives the tokens
+// amount: the amount to mint
+//
+// Deprecated: use a minter resource instead
+func (c Transactions) AdminMint(`)
+	})
+
+	t.Run("go types", func(t *testing.T) {
+		inputs := map[string]string{
+			"UFix64":                "float64",
+			"Address":               "string",
+			"[Address]":             "cadence.Value",
+			"[UInt64]":              "[]uint64",
+			"String?":               "*string",
+			"{String: Int}":         "map[string]int",
+			"@NonFungibleToken.NFT": "cadence.Value",
+		}
+		for cadenceType, goType := range inputs {
+			assert.Equal(t, goType, goInputType(parseCadenceType(cadenceType), false), cadenceType)
+		}
+		outputs := map[string]string{
+			"Address":               "string",
+			"Int":                   "string",
+			"[UFix64]":              "[]float64",
+			"{UInt64: String?}":     "map[uint64]*string",
+			"MetadataViews.Display": "interface{}",
+		}
+		for cadenceType, goType := range outputs {
+			assert.Equal(t, goType, goOutputType(parseCadenceType(cadenceType)), cadenceType)
+		}
+		assert.Equal(t, "MintTokens", goExportedName("mint_tokens"))
+		assert.Equal(t, "AdminMint", goExportedName("admin/mint"))
+		assert.Equal(t, "typeArg", goParameterName("type", nil))
+		assert.Equal(t, []string{"signer1", "signer2"}, goSignerNames(2))
+	})
+}
@@ -0,0 +1,277 @@
+package overflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/flixkit-go/flixkit"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/gateway"
+	"github.com/pkg/errors"
+)
+
+// FLIX templates from local interactions
+//
+// GenerateFlix creates a FLIX json (version 1.1.0) for a transaction or script in the project with flixkit.
+// The title, description and parameter descriptions are read from the doc comment of the transaction or main function
+//
+//	/// Mint tokens
+//	///
+//	/// Mint new tokens into the account of the recipient
+//	///
+//	/// @param recipient: the account that receives the tokens
+//	/// @param amount: the number of tokens to mint
+//	transaction(recipient: Address, amount: UFix64) {
+//
+// The addresses of dependencies come from the aliases and deployments in flow.json.
+// Every network in the template is pinned through its access node in flow.json, the embedded emulator is pinned directly.
+// If a network can not be reached no template is created, pass the names of the networks to only include those
+//
+//	o.GenerateFlix("mint_tokens", "emulator", "testnet")
+
+// the language tag of the messages read from doc comments
+const flixLanguageTag = "en-US"
+
+func flixMessages(title string, description string) []flixMessage {
+	messages := []flixMessage{}
+	if title != "" {
+		messages = append(messages, flixMessage{Key: "title", I18n: []flixI18n{{Tag: flixLanguageTag, Translation: title}}})
+	}
+	if description != "" {
+		messages = append(messages, flixMessage{Key: "description", I18n: []flixI18n{{Tag: flixLanguageTag, Translation: description}}})
+	}
+	return messages
+}
+
+var flixImportRegexp = regexp.MustCompile(`import\s+(\w+)\s+from\s+\S+`)
+
+// flix uses the import "Name" syntax, imports from addresses and files are rewritten
+func flixBody(code string) string {
+	return flixImportRegexp.ReplaceAllString(formatCode(code), `import "$1"`)
+}
+
+// the path and code of the transaction or script with the given name
+func (o *OverflowState) interactionCode(name string) (string, []byte, error) {
+	for _, basePath := range []string{o.TransactionBasePath, o.ScriptBasePath} {
+		path := fmt.Sprintf("%s/%s.cdc", basePath, name)
+		code, err := o.State.ReaderWriter().ReadFile(path)
+		if err == nil {
+			return path, code, nil
+		}
+	}
+	return "", nil, fmt.Errorf("could not find transaction or script with name %s", name)
+}
+
+// the template flixkit starts from, with the messages from the doc comment of the interaction
+func flixPreFill(path string, code []byte) (string, error) {
+	info := declarationInfo(code)
+	doc := info.Doc
+	if doc == nil {
+		doc = &OverflowDeclarationDoc{}
+	}
+	for parameter := range doc.Parameters {
+		if _, ok := info.Parameters[parameter]; !ok {
+			return "", fmt.Errorf("the doc comment of %s describes the unknown parameter %s", path, parameter)
+		}
+	}
+
+	template := flixTemplate{
+		FType:    "InteractionTemplate",
+		FVersion: "1.1.0",
+		Data: flixData{
+			Messages:     flixMessages(doc.Title, doc.Description),
+			Dependencies: []flixDependency{},
+			Parameters:   []flixParameter{},
+		},
+	}
+	for index, parameter := range info.ParameterOrder {
+		template.Data.Parameters = append(template.Data.Parameters, flixParameter{
+			Label:    parameter,
+			Index:    index,
+			Messages: flixMessages("", doc.Parameters[parameter]),
+		})
+	}
+	preFill, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	return string(preFill), nil
+}
+
+// the networks in flow.json with the given names, all networks if no names are given
+func (o *OverflowState) flixNetworks(names []string) ([]config.Network, error) {
+	if len(names) == 0 {
+		return o.sortedNetworks(), nil
+	}
+	networks := []config.Network{}
+	for _, name := range names {
+		network, err := o.State.Networks().ByName(name)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, *network)
+	}
+	return networks, nil
+}
+
+// the addresses of the imported contracts on the given networks
+func (o *OverflowState) flixContractInfos(body string, networks []config.Network) (flixkit.ContractInfos, error) {
+	program, err := parser.ParseProgram(nil, []byte(body), parser.Config{})
+	if err != nil {
+		return nil, err
+	}
+	contractInfos := flixkit.ContractInfos{}
+	for _, declaration := range program.ImportDeclarations() {
+		contract := contractNameOfLocation(declaration.Location)
+		addresses := flixkit.NetworkAddressMap{}
+		for _, network := range networks {
+			address, err := o.contractAddress(contract, network)
+			if err != nil {
+				continue
+			}
+			addresses[network.Name] = address.HexWithPrefix()
+		}
+		if len(addresses) == 0 {
+			return nil, fmt.Errorf("could not find contract dependency %s in the aliases or deployments in flow.json", contract)
+		}
+		contractInfos[contract] = addresses
+	}
+	return contractInfos, nil
+}
+
+// GenerateFlix creates the FLIX json for the transaction or script with the given name, for the given networks or all networks in flow.json
+func (o *OverflowState) GenerateFlix(name string, networks ...string) (string, error) {
+	path, code, err := o.interactionCode(name)
+	if err != nil {
+		return "", err
+	}
+	body := flixBody(string(code))
+
+	preFill, err := flixPreFill(path, code)
+	if err != nil {
+		return "", err
+	}
+	templateNetworks, err := o.flixNetworks(networks)
+	if err != nil {
+		return "", err
+	}
+	contractInfos, err := o.flixContractInfos(body, templateNetworks)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not find the dependencies of %s", path)
+	}
+
+	// flixkit only pins over grpc and ignores networks it can not reach, the pins are calculated below instead
+	ctx := context.Background()
+	raw, err := o.Flixkit.CreateTemplate(ctx, contractInfos, body, preFill, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not create the flix for %s", path)
+	}
+	var template flixTemplate
+	err = json.Unmarshal([]byte(raw), &template)
+	if err != nil {
+		return "", err
+	}
+	sortFlixNetworks(&template)
+
+	template.Data.Cadence.NetworkPins = []flixNetworkPin{}
+	for _, network := range templateNetworks {
+		cadence, ok := template.cadenceForNetwork(network.Name)
+		if !ok {
+			continue
+		}
+		template.Data.Cadence.NetworkPins = append(template.Data.Cadence.NetworkPins, flixNetworkPin{Network: network.Name, PinSelf: sha3Hex(cadence)})
+		err = o.pinFlixDependencies(ctx, &template, network)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not pin the dependencies of %s on network %s", path, network.Name)
+		}
+	}
+	return encodeFlix(&template)
+}
+
+// the gateway used to pin dependencies on a network, the gateway of overflow for the network it is connected to
+func (o *OverflowState) flixGateway(network config.Network) (gateway.Gateway, error) {
+	if network.Name == o.Network.Name {
+		return o.Flowkit.Gateway(), nil
+	}
+	return gateway.NewGrpcGateway(network, o.GrpcDialOptions...)
+}
+
+// pin all dependencies of the template that have an address on the network at its latest block
+func (o *OverflowState) pinFlixDependencies(ctx context.Context, template *flixTemplate, network config.Network) error {
+	if len(template.Data.Dependencies) == 0 {
+		return nil
+	}
+	gw, err := o.flixGateway(network)
+	if err != nil {
+		return err
+	}
+	block, err := gw.GetLatestBlock(ctx)
+	if err != nil {
+		return err
+	}
+	for _, dependency := range template.Data.Dependencies {
+		for _, contract := range dependency.Contracts {
+			for index, dependencyNetwork := range contract.Networks {
+				if dependencyNetwork.Network != network.Name {
+					continue
+				}
+				pin, err := flixDependencyPin(ctx, gw, network.Name, flow.HexToAddress(dependencyNetwork.Address), contract.Contract)
+				if err != nil {
+					return err
+				}
+				contract.Networks[index].DependencyPinBlockHeight = block.Height
+				contract.Networks[index].DependencyPin = pin
+			}
+		}
+	}
+	return nil
+}
+
+// flixkit adds the networks of a dependency in random order, they are sorted by name so the same code gives the same template and id
+func sortFlixNetworks(template *flixTemplate) {
+	for _, dependency := range template.Data.Dependencies {
+		for _, contract := range dependency.Contracts {
+			sort.Slice(contract.Networks, func(i, j int) bool { return contract.Networks[i].Network < contract.Networks[j].Network })
+		}
+	}
+}
+
+// the json of the template with its id calculated from the content
+func encodeFlix(template *flixTemplate) (string, error) {
+	var err error
+	template.ID, err = template.calculateID()
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	encoder := json.NewEncoder(&result)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	err = encoder.Encode(template)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// GenerateFlixFile writes the FLIX json for the transaction or script with the given name to a file
+func (o *OverflowState) GenerateFlixFile(name string, file string, networks ...string) error {
+	template, err := o.GenerateFlix(name, networks...)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(template), 0o644)
+}
@@ -0,0 +1,161 @@
+package overflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFlix(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	t.Run("transaction", func(t *testing.T) {
+		result, err := o.GenerateFlix("../testdata/flix/mint_tokens", "emulator")
+		require.NoError(t, err)
+
+		var template flixTemplate
+		require.NoError(t, json.Unmarshal([]byte(result), &template))
+		assert.Equal(t, "transaction", template.Data.Type)
+		assert.Equal(t, []flixMessage{
+			{Key: "title", I18n: []flixI18n{{Tag: "en-US", Translation: "Mint tokens"}}},
+			{Key: "description", I18n: []flixI18n{{Tag: "en-US", Translation: "Mint new FLOW tokens into the account of the recipient,\nthe signer has to be the token admin"}}},
+		}, template.Data.Messages)
+		assert.Equal(t, []flixParameter{
+			{Label: "recipient", Index: 0, Type: "Address", Messages: []flixMessage{{Key: "description", I18n: []flixI18n{{Tag: "en-US", Translation: "the account that receives the tokens"}}}}},
+			{Label: "amount", Index: 1, Type: "UFix64", Messages: []flixMessage{{Key: "description", I18n: []flixI18n{{Tag: "en-US", Translation: "the number of tokens to mint"}}}}},
+		}, template.Data.Parameters)
+		assert.Contains(t, template.Data.Cadence.Body, "import \"FungibleToken\"\nimport \"FlowToken\"")
+		require.Len(t, template.Data.Cadence.NetworkPins, 1)
+		assert.Equal(t, "emulator", template.Data.Cadence.NetworkPins[0].Network)
+
+		require.Len(t, template.Data.Dependencies, 2)
+		fungibleToken := template.Data.Dependencies[0].Contracts[0]
+		assert.Equal(t, "FungibleToken", fungibleToken.Contract)
+		require.Len(t, fungibleToken.Networks, 1)
+		assert.Equal(t, "emulator", fungibleToken.Networks[0].Network)
+		assert.Equal(t, "0xee82856bf20e2aa6", fungibleToken.Networks[0].Address)
+		require.NotNil(t, fungibleToken.Networks[0].DependencyPin)
+		assert.Equal(t, "FungibleToken", fungibleToken.Networks[0].DependencyPin.PinContractName)
+		assert.NotZero(t, fungibleToken.Networks[0].DependencyPinBlockHeight)
+		assert.NoError(t, o.verifyFlix(context.Background(), &template, "emulator"))
+
+		id, err := template.calculateID()
+		require.NoError(t, err)
+		assert.Equal(t, id, template.ID)
+
+		again, err := o.GenerateFlix("../testdata/flix/mint_tokens", "emulator")
+		require.NoError(t, err)
+		assert.Equal(t, result, again)
+	})
+
+	t.Run("script", func(t *testing.T) {
+		result, err := o.GenerateFlix("../testdata/flix/balance")
+		require.NoError(t, err)
+
+		var template flixTemplate
+		require.NoError(t, json.Unmarshal([]byte(result), &template))
+		assert.Equal(t, "script", template.Data.Type)
+		assert.Equal(t, &flixParameter{Label: "result", Type: "UFix64", Messages: []flixMessage{}}, template.Data.Output)
+		assert.Equal(t, "Balance", template.Data.Messages[0].I18n[0].Translation)
+		assert.Equal(t, "The balance of an account", template.Data.Messages[1].I18n[0].Translation)
+		assert.Empty(t, template.Data.Dependencies)
+	})
+
+	t.Run("id is the same as flixkit", func(t *testing.T) {
+		_, code, err := o.interactionCode("../testdata/flix/balance")
+		require.NoError(t, err)
+		result, err := o.Flixkit.CreateTemplate(context.Background(), nil, string(code), "", nil)
+		require.NoError(t, err)
+
+		var template flixTemplate
+		require.NoError(t, json.Unmarshal([]byte(result), &template))
+		id, err := template.calculateID()
+		require.NoError(t, err)
+		assert.Equal(t, template.ID, id)
+	})
+
+	// the template flixkit creates for mint_tokens with the addresses of its dependencies on the given network
+	flixkitMintTokens := func(t *testing.T, network string, pin bool) *flixTemplate {
+		path, code, err := o.interactionCode("../testdata/flix/mint_tokens")
+		require.NoError(t, err)
+		body := flixBody(string(code))
+		preFill, err := flixPreFill(path, code)
+		require.NoError(t, err)
+		networks, err := o.flixNetworks([]string{network})
+		require.NoError(t, err)
+		contractInfos, err := o.flixContractInfos(body, networks)
+		require.NoError(t, err)
+		if !pin {
+			networks = nil
+		}
+		result, err := o.Flixkit.CreateTemplate(context.Background(), contractInfos, body, preFill, networks)
+		require.NoError(t, err)
+
+		var template flixTemplate
+		require.NoError(t, json.Unmarshal([]byte(result), &template))
+		return &template
+	}
+
+	t.Run("id with parameters, messages and dependencies is the same as flixkit", func(t *testing.T) {
+		template := flixkitMintTokens(t, "emulator", false)
+		assert.Len(t, template.Data.Parameters, 2)
+		assert.Len(t, template.Data.Messages, 2)
+		assert.Len(t, template.Data.Dependencies, 2)
+
+		id, err := template.calculateID()
+		require.NoError(t, err)
+		assert.Equal(t, template.ID, id)
+
+		// the order of the parameters in the json does not change the id
+		template.Data.Parameters[0], template.Data.Parameters[1] = template.Data.Parameters[1], template.Data.Parameters[0]
+		reordered, err := template.calculateID()
+		require.NoError(t, err)
+		assert.Equal(t, id, reordered)
+	})
+
+	t.Run("id with dependency pins is the same as flixkit", func(t *testing.T) {
+		generated, err := o.GenerateFlix("../testdata/flix/mint_tokens", "mainnet")
+		if err != nil {
+			// flixkit only pins over grpc, it can not be compared without network access
+			t.Skipf("mainnet can not be reached: %v", err)
+		}
+		template := flixkitMintTokens(t, "mainnet", true)
+		require.NotNil(t, template.Data.Dependencies[0].Contracts[0].Networks[0].DependencyPin)
+
+		id, err := template.calculateID()
+		require.NoError(t, err)
+		assert.Equal(t, template.ID, id)
+
+		var pinned flixTemplate
+		require.NoError(t, json.Unmarshal([]byte(generated), &pinned))
+		assert.Equal(t, template.ID, pinned.ID)
+	})
+
+	t.Run("all networks are pinned", func(t *testing.T) {
+		result, err := o.GenerateFlix("../testdata/flix/mint_tokens")
+		if err != nil {
+			// the access nodes of mainnet and testnet can not be reached without network access
+			assert.ErrorContains(t, err, "testdata/flix/mint_tokens.cdc on network mainnet")
+			return
+		}
+		var template flixTemplate
+		require.NoError(t, json.Unmarshal([]byte(result), &template))
+		for _, network := range []string{"emulator", "mainnet", "testnet"} {
+			assert.NoError(t, o.verifyFlix(context.Background(), &template, network))
+		}
+	})
+
+	t.Run("unknown network", func(t *testing.T) {
+		_, err := o.GenerateFlix("../testdata/flix/mint_tokens", "previewnet")
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown interaction", func(t *testing.T) {
+		_, err := o.GenerateFlix("does_not_exist")
+		assert.ErrorContains(t, err, "could not find transaction or script with name does_not_exist")
+	})
+}
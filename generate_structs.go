@@ -0,0 +1,308 @@
+package overflow
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/cmd"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+	"github.com/pkg/errors"
+)
+
+// Go structs from cadence types
+//
+// GenerateStructs type checks contracts from flow.json and creates go structs for their structs, resources and events.
+// A type is named Contract_Name so that it works with the default InputResolver and it has the qualified identifier for every network
+//
+//	source, err := o.GenerateStructs("types", "Debug.FooBar", "FlowToken.TokensDeposited")
+//
+// Composite types used in fields are generated as well, giving only a contract name generates all the types in it
+
+// a checker for the contracts in flow.json, imports are resolved by contract name
+type contractChecker struct {
+	o        *OverflowState
+	codes    map[common.Location][]byte
+	checkers map[common.Location]*sema.Checker
+	errors   map[common.Location]error
+	config   *sema.Config
+}
+
+func (o *OverflowState) newContractChecker() *contractChecker {
+	c := &contractChecker{
+		o:        o,
+		codes:    map[common.Location][]byte{},
+		checkers: map[common.Location]*sema.Checker{},
+		errors:   map[common.Location]error{},
+	}
+	c.config = cmd.DefaultCheckerConfig(c.checkers, c.codes, stdlib.DefaultStandardLibraryValues(&cmd.StandardLibraryHandler{}))
+	c.config.ImportHandler = func(checker *sema.Checker, location common.Location, _ ast.Range) (sema.Import, error) {
+		if location == stdlib.CryptoCheckerLocation {
+			return sema.ElaborationImport{Elaboration: stdlib.CryptoChecker().Elaboration}, nil
+		}
+		// contracts that are only imported do not have to type check, the types they declare can still be used
+		imported, err := c.check(contractNameOfLocation(location))
+		if imported == nil {
+			return nil, err
+		}
+		return sema.ElaborationImport{Elaboration: imported.Elaboration}, nil
+	}
+	return c
+}
+
+// the name of the contract that is imported from a location, imports can be a name, a file or an address
+func contractNameOfLocation(location common.Location) string {
+	switch l := location.(type) {
+	case common.AddressLocation:
+		return l.Name
+	case common.StringLocation:
+		return strings.TrimSuffix(filepath.Base(string(l)), ".cdc")
+	}
+	return location.String()
+}
+
+// check the contract with the given name from flow.json, all contracts are checked at most once
+// the checker is returned if the contract could be parsed even if it does not type check
+func (c *contractChecker) check(name string) (*sema.Checker, error) {
+	location := common.IdentifierLocation(name)
+	if checker, ok := c.checkers[location]; ok {
+		return checker, c.errors[location]
+	}
+
+	contract, err := c.o.State.Contracts().ByName(name)
+	if err != nil {
+		return nil, err
+	}
+	code, err := c.o.State.ReaderWriter().ReadFile(contract.Location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read contract %s", name)
+	}
+	c.codes[location] = code
+
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse contract %s", name)
+	}
+	checker, err := sema.NewChecker(program, location, nil, c.config)
+	if err != nil {
+		return nil, err
+	}
+	c.checkers[location] = checker
+	err = checker.Check()
+	if err != nil {
+		c.errors[location] = errors.Wrapf(err, "contract %s does not type check", name)
+	}
+	return checker, c.errors[location]
+}
+
+// the composite type of the contract with the given name
+func (c *contractChecker) contractType(name string) (*sema.CompositeType, error) {
+	checker, err := c.check(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, declaration := range checker.Program.CompositeDeclarations() {
+		if declaration.Identifier.Identifier == name {
+			return checker.Elaboration.CompositeDeclarationType(declaration), nil
+		}
+	}
+	return nil, fmt.Errorf("could not find contract %s in its file", name)
+}
+
+// the go name of a composite type, A.0x1.Debug.Foo is Debug_Foo
+func goStructName(compositeType *sema.CompositeType) string {
+	return strings.ReplaceAll(compositeType.QualifiedIdentifier(), ".", "_")
+}
+
+// generates go structs for composite types and the types they use
+type structGenerator struct {
+	checker *contractChecker
+	queue   []*sema.CompositeType
+	seen    map[*sema.CompositeType]bool
+}
+
+func (g *structGenerator) add(compositeType *sema.CompositeType) {
+	if !g.seen[compositeType] {
+		g.seen[compositeType] = true
+		g.queue = append(g.queue, compositeType)
+	}
+}
+
+// the go type of a field, composite types from the checked contracts are generated as well
+func (g *structGenerator) goType(fieldType sema.Type) string {
+	switch t := fieldType.(type) {
+	case *sema.OptionalType:
+		inner := g.goType(t.Type)
+		if inner == "interface{}" {
+			return inner
+		}
+		return "*" + inner
+	case *sema.VariableSizedType:
+		return "[]" + g.goType(t.Type)
+	case *sema.ConstantSizedType:
+		return "[]" + g.goType(t.Type)
+	case *sema.DictionaryType:
+		// json object keys can only be unmarshalled into strings and integers
+		key := g.goType(t.KeyType)
+		if key == "bool" || key == "float64" || key == "interface{}" || strings.HasPrefix(key, "*") {
+			key = "string"
+		}
+		return fmt.Sprintf("map[%s]%s", key, g.goType(t.ValueType))
+	case *sema.CompositeType:
+		if _, ok := t.Location.(common.IdentifierLocation); ok && t.Kind != common.CompositeKindContract && t.Location != stdlib.CryptoCheckerLocation {
+			g.add(t)
+			return goStructName(t)
+		}
+		return "interface{}"
+	}
+	if goType, ok := goOutputTypes[fieldType.QualifiedString()]; ok {
+		return goType
+	}
+	return "interface{}"
+}
+
+// a field in a generated struct
+type goStructField struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+func (g *structGenerator) field(name string, fieldType sema.Type) goStructField {
+	tag := name
+	// address fields are sent as addresses and not strings when the struct is used as an argument
+	if _, ok := fieldType.(*sema.AddressType); ok {
+		tag = name + ",cadenceAddress"
+	}
+	return goStructField{Name: goExportedName(name), Type: g.goType(fieldType), Tag: tag}
+}
+
+// the fields of a composite, the parameters of events and the public fields of resources
+func (g *structGenerator) fields(compositeType *sema.CompositeType) []goStructField {
+	fields := []goStructField{}
+	if compositeType.Kind == common.CompositeKindEvent {
+		for _, parameter := range compositeType.ConstructorParameters {
+			fields = append(fields, g.field(parameter.Identifier, parameter.TypeAnnotation.Type))
+		}
+		return fields
+	}
+	for _, name := range compositeType.Fields {
+		member, ok := compositeType.Members.Get(name)
+		if !ok {
+			continue
+		}
+		if compositeType.Kind == common.CompositeKindResource && !member.Access.Equal(sema.PrimitiveAccess(ast.AccessAll)) {
+			continue
+		}
+		fields = append(fields, g.field(name, member.TypeAnnotation.Type))
+	}
+	return fields
+}
+
+// the composite types for a list of type names, a name is either a contract or a contract with a type like Debug.Foo
+func (g *structGenerator) selectTypes(types []string) error {
+	for _, typeName := range types {
+		contract, nested, _ := strings.Cut(typeName, ".")
+		contractType, err := g.checker.contractType(contract)
+		if err != nil {
+			return err
+		}
+		if nested != "" {
+			nestedType, ok := contractType.NestedTypes.Get(nested)
+			compositeType, isComposite := nestedType.(*sema.CompositeType)
+			if !ok || !isComposite {
+				return fmt.Errorf("could not find struct, resource or event %s", typeName)
+			}
+			g.add(compositeType)
+			continue
+		}
+		contractType.NestedTypes.Foreach(func(_ string, nestedType sema.Type) {
+			if compositeType, ok := nestedType.(*sema.CompositeType); ok {
+				g.add(compositeType)
+			}
+		})
+	}
+	return nil
+}
+
+// GenerateStructs creates the source of a go package with a struct for the given cadence types
+// A type is a contract name for all its structs, resources and events, or a contract with a type like Debug.Foo
+func (o *OverflowState) GenerateStructs(packageName string, types ...string) (string, error) {
+	generator := &structGenerator{
+		checker: o.newContractChecker(),
+		seen:    map[*sema.CompositeType]bool{},
+	}
+	err := generator.selectTypes(types)
+	if err != nil {
+		return "", err
+	}
+
+	structs := map[string]string{}
+	for i := 0; i < len(generator.queue); i++ {
+		compositeType := generator.queue[i]
+		name := goStructName(compositeType)
+		contract, typeName, _ := strings.Cut(compositeType.QualifiedIdentifier(), ".")
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "\n// %s is the %s %s\n", name, compositeType.Kind.Keyword(), compositeType.QualifiedIdentifier())
+		fmt.Fprintf(&sb, "type %s struct {\n", name)
+		for _, field := range generator.fields(compositeType) {
+			fmt.Fprintf(&sb, "%s %s `cadence:\"%s\"`\n", field.Name, field.Type, field.Tag)
+		}
+		fmt.Fprintln(&sb, "}")
+
+		fmt.Fprintf(&sb, "\n// QualifiedIdentifier is the cadence type identifier of %s on the given network\n", compositeType.QualifiedIdentifier())
+		fmt.Fprintf(&sb, "func (%s) QualifiedIdentifier(network string) string {\n", name)
+		fmt.Fprintln(&sb, "switch network {")
+		for _, network := range o.sortedNetworks() {
+			identifier, err := o.qualifiedIdentifier(contract, typeName, network)
+			if err == nil {
+				fmt.Fprintf(&sb, "case %q:\nreturn %q\n", network.Name, identifier)
+			}
+		}
+		fmt.Fprintln(&sb, "}")
+		fmt.Fprintln(&sb, `return ""`)
+		fmt.Fprintln(&sb, "}")
+		structs[name] = sb.String()
+	}
+
+	names := []string{}
+	for name := range structs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var source strings.Builder
+	fmt.Fprintln(&source, "// Code generated by overflow. DO NOT EDIT.")
+	fmt.Fprintln(&source)
+	fmt.Fprintf(&source, "package %s\n", packageName)
+	for _, name := range names {
+		source.WriteString(structs[name])
+	}
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return "", errors.Wrap(err, "could not format generated structs")
+	}
+	return string(formatted), nil
+}
+
+// GenerateStructsFile writes the structs generated with GenerateStructs to the given file
+func (o *OverflowState) GenerateStructsFile(packageName string, file string, types ...string) error {
+	source, err := o.GenerateStructs(packageName, types...)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(source), 0o644)
+}
@@ -0,0 +1,38 @@
+package overflow
+
+import (
+	"testing"
+
+	"github.com/hexops/autogold"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateStructs(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	t.Run("contract", func(t *testing.T) {
+		source, err := o.GenerateStructs("types", "Debug")
+		require.NoError(t, err)
+		autogold.Equal(t, autogold.Raw(source))
+	})
+
+	t.Run("type and the types it uses", func(t *testing.T) {
+		source, err := o.GenerateStructs("types", "Debug.FooListBar")
+		require.NoError(t, err)
+		assert.Contains(t, source, "type Debug_FooListBar struct")
+		assert.Contains(t, source, "type Debug_Foo2 struct")
+		assert.NotContains(t, source, "type Debug_Foo struct")
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := o.GenerateStructs("types", "Debug.Bar")
+		assert.ErrorContains(t, err, "could not find struct, resource or event Debug.Bar")
+	})
+
+	t.Run("contract that does not type check", func(t *testing.T) {
+		_, err := o.GenerateStructs("types", "NonFungibleToken")
+		assert.ErrorContains(t, err, "contract NonFungibleToken does not type check")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"math"
 	"strings"
 	"testing"
@@ -87,6 +88,9 @@ type OverflowInteractionBuilder struct {
 	//
 	StopOnError *bool
 
+	// Read the storage of the authorizers and payer before and after a transaction on the emulator
+	TrackStorage bool
+
 	Testing OverflowTestingAsssertions
 }
 
@@ -126,6 +130,14 @@ func WithoutLog() OverflowInteractionOption {
 	}
 }
 
+// read the storage used and capacity of the authorizers and payer before and after the transaction, only on the embedded emulator
+// the transaction runs exclusively on the emulator so the storage is not changed by other interactions
+func WithStorageTracking() OverflowInteractionOption {
+	return func(oib *OverflowInteractionBuilder) {
+		oib.TrackStorage = true
+	}
+}
+
 func WithContext(ctx context.Context) OverflowInteractionOption {
 	return func(oib *OverflowInteractionBuilder) {
 		oib.Ctx = ctx
@@ -315,6 +327,76 @@ func WithSigner(signer string) OverflowInteractionOption {
 	}
 }
 
+// set payer, proposer authorizer as the signer but sign with the given key, see AddAccountKeyE
+func WithSignerKey(signer string, key accounts.Key) OverflowInteractionOption {
+	return func(oib *OverflowInteractionBuilder) {
+		account, err := oib.Overflow.AccountE(signer)
+		if err != nil {
+			oib.Error = err
+			return
+		}
+		signerAccount := *account
+		signerAccount.Key = key
+		oib.Payer = &signerAccount
+		oib.Proposer = &signerAccount
+	}
+}
+
+// set payer, proposer authorizer as the signer but sign using the key with the given index
+// the key at the index must have the same private key as the one configured for the signer
+func WithSignerKeyIndex(signer string, index int) OverflowInteractionOption {
+	return func(oib *OverflowInteractionBuilder) {
+		account, err := oib.Overflow.AccountE(signer)
+		if err != nil {
+			oib.Error = err
+			return
+		}
+		privateKey, err := account.Key.PrivateKey()
+		if err != nil {
+			oib.Error = err
+			return
+		}
+		signerAccount := *account
+		signerAccount.Key = accounts.NewHexKeyFromPrivateKey(index, account.Key.HashAlgo(), *privateKey)
+		oib.Payer = &signerAccount
+		oib.Proposer = &signerAccount
+	}
+}
+
+// set payer, proposer authorizer as the given account name or address without having its key, use WithImpersonatedAuthorizer for more authorizers
+// NB! only works in fork mode where transaction validation is turned off, see WithFork
+func WithImpersonatedSigner(signer string) OverflowInteractionOption {
+	return func(oib *OverflowInteractionBuilder) {
+		account, err := oib.Overflow.impersonatedAccount(signer)
+		if err != nil {
+			oib.Error = err
+			return
+		}
+		oib.Payer = account
+		oib.Proposer = account
+	}
+}
+
+// set an aditional authorizer as the given account name or address without having its key
+// NB! only works in fork mode where transaction validation is turned off, see WithFork
+func WithImpersonatedPayloadSigner(signer ...string) OverflowInteractionOption {
+	return func(oib *OverflowInteractionBuilder) {
+		for _, signer := range signer {
+			account, err := oib.Overflow.impersonatedAccount(signer)
+			if err != nil {
+				oib.Error = err
+				return
+			}
+			oib.PayloadSigners = append(oib.PayloadSigners, account)
+		}
+	}
+}
+
+// alias for adding impersonated payload signers
+func WithImpersonatedAuthorizer(signer ...string) OverflowInteractionOption {
+	return WithImpersonatedPayloadSigner(signer...)
+}
+
 // set service account as payer, proposer, authorizer
 func WithSignerServiceAccount() OverflowInteractionOption {
 	return func(oib *OverflowInteractionBuilder) {
@@ -483,10 +565,21 @@ func (oib OverflowInteractionBuilder) Send() *OverflowResult {
 		Name:             "",
 		Arguments:        oib.NamedCadenceArguments,
 		UnderflowOptions: oib.Overflow.UnderflowOptions,
+		overflow:         oib.Overflow,
 	}
 	if oib.StopOnError != nil {
 		result.StopOnError = *oib.StopOnError
 	}
+
+	oib.Overflow.logInteractionStarted(&oib, interactionTransaction)
+	defer func() {
+		attributes := []slog.Attr{slog.Int("computation", result.ComputationUsed)}
+		if result.Id != flow.EmptyID {
+			attributes = append(attributes, slog.String("id", result.Id.String()))
+		}
+		oib.Overflow.logInteractionFinished(&oib, interactionTransaction, result.Err, attributes...)
+	}()
+
 	if oib.Error != nil {
 		result.Err = oib.Error
 		return result
@@ -501,7 +594,8 @@ func (oib OverflowInteractionBuilder) Send() *OverflowResult {
 
 	result.DeclarationInfo = *declarationInfo(oib.TransactionCode)
 
-	oib.Overflow.Log.Reset()
+	trackStorage := oib.TrackStorage && oib.Overflow.EmulatorGatway != nil
+	defer oib.Overflow.lockInteraction(trackStorage)()
 	/*
 		❗ Special case: if an account is both the payer and either a proposer or authorizer, it is only required to sign the envelope.
 	*/
@@ -562,6 +656,16 @@ func (oib OverflowInteractionBuilder) Send() *OverflowResult {
 	txId := tx.FlowTransaction().ID()
 	result.Id = txId
 
+	var storageBefore map[string][2]uint64
+	storageAddresses := storageTrackedAddresses(authorizers, payer.Address)
+	if trackStorage {
+		storageBefore, err = oib.Overflow.storageUsage(oib.Ctx, storageAddresses)
+		if err != nil {
+			result.Err = errors.Wrap(err, "could not read storage usage")
+			return result
+		}
+	}
+
 	ftx, res, err := oib.Overflow.Flowkit.SendSignedTransaction(oib.Ctx, tx)
 	result.Transaction = ftx
 	result.TransactionResult = res
@@ -571,7 +675,16 @@ func (oib OverflowInteractionBuilder) Send() *OverflowResult {
 		return result
 	}
 
-	logMessage, err := oib.Overflow.readLog()
+	if trackStorage {
+		storageAfter, err := oib.Overflow.storageUsage(oib.Ctx, storageAddresses)
+		if err != nil {
+			result.Err = errors.Wrap(err, "could not read storage usage")
+			return result
+		}
+		result.StorageUsage = storageUsages(storageBefore, storageAfter)
+	}
+
+	logMessage, err := oib.Overflow.EmulatorLog.Transaction(txId)
 	if err != nil {
 		result.Err = err
 	}
@@ -635,7 +748,6 @@ func (oib OverflowInteractionBuilder) Send() *OverflowResult {
 	result.Events = overflowEvents
 
 	result.Name = oib.Name
-	oib.Overflow.Log.Reset()
 	result.Err = errors.Wrapf(res.Error, "transaction=%s", codeFileName)
 
 	if result.Err != nil && result.StopOnError {
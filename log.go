@@ -1,7 +1,13 @@
 package overflow
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sync"
+
+	"github.com/onflow/flow-go-sdk"
 )
 
 // OverflowEmulatorLogMessage a log message from the logrus implementation used in the flow emulator
@@ -22,3 +28,224 @@ func (me OverflowEmulatorLogMessage) String() string {
 
 	return fmt.Sprintf("%s - %s%s", me.Level, me.Msg, fields)
 }
+
+// the last line the fvm logs when executing a transaction
+const executionDataMessage = "transaction execution data"
+
+// how many transactions we keep the log for if nobody reads them
+const maxUnreadTransactionLogs = 1000
+
+// OverflowEmulatorLog captures the log of the embedded emulator and splits it up per transaction
+//
+// The emulator executes the transactions in a block one after the other and the log from each transaction ends with the execution data line.
+// After the block is committed the emulator logs the id of each executed transaction in the same order, this is used to correlate log lines with transaction ids.
+// Log lines from scripts can not be correlated so they are read from the lines that do not belong to a transaction.
+// The gateway adapters log to their own writer, only their lines about a transaction are kept and added to the log of that transaction.
+type OverflowEmulatorLog struct {
+	mutex sync.Mutex
+
+	// every line as it is written, this backs the deprecated OverflowState.Log and is reset when the log of an interaction is read
+	raw *bytes.Buffer
+
+	// lines that do not belong to an executed transaction yet
+	pending [][]byte
+
+	// the lines of executed transactions that are not marked with an id yet
+	executed [][][]byte
+
+	// the lines for each transaction that has not been read yet
+	transactions map[flow.Identifier][][]byte
+
+	// the lines from the gateway adapters for transactions that are not executed yet
+	submitted map[flow.Identifier][][]byte
+
+	// the ids of unread transactions in the order they were executed
+	unread []flow.Identifier
+}
+
+func newOverflowEmulatorLog() *OverflowEmulatorLog {
+	return &OverflowEmulatorLog{
+		raw:          &bytes.Buffer{},
+		transactions: map[flow.Identifier][][]byte{},
+		submitted:    map[flow.Identifier][][]byte{},
+	}
+}
+
+// Write a line to the log, zerolog writes one json line in each call
+func (l *OverflowEmulatorLog) Write(p []byte) (int, error) {
+	line := make([]byte, len(p))
+	copy(line, p)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.raw.Write(line)
+	l.pending = append(l.pending, line)
+	if bytes.Contains(line, []byte(executionDataMessage)) {
+		l.executed = append(l.executed, l.pending)
+		l.pending = nil
+	}
+	return len(p), nil
+}
+
+// the writer for the server logger of the emulator, it only looks for the lines that mark a transaction as executed
+func (l *OverflowEmulatorLog) markerWriter() io.Writer {
+	return emulatorLogMarker{log: l}
+}
+
+// the writer for the logger of the gateway adapters, lines without a transaction id are dropped so they are not added to the next executed transaction
+func (l *OverflowEmulatorLog) adapterWriter() io.Writer {
+	return emulatorLogAdapter{log: l}
+}
+
+func (l *OverflowEmulatorLog) markExecuted(id flow.Identifier) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var lines [][]byte
+	if len(l.executed) > 0 {
+		lines = l.executed[0]
+		l.executed = l.executed[1:]
+	} else {
+		lines = l.pending
+		l.pending = nil
+	}
+
+	l.transactions[id] = append(l.submitted[id], lines...)
+	delete(l.submitted, id)
+	l.unread = append(l.unread, id)
+	if len(l.unread) > maxUnreadTransactionLogs {
+		delete(l.transactions, l.unread[0])
+		l.unread = l.unread[1:]
+	}
+}
+
+// Transaction returns the log lines for the transaction with the given id, the lines can only be read once
+func (l *OverflowEmulatorLog) Transaction(id flow.Identifier) ([]OverflowEmulatorLogMessage, error) {
+	l.mutex.Lock()
+	lines := l.transactions[id]
+	delete(l.transactions, id)
+	for i, unread := range l.unread {
+		if unread == id {
+			l.unread = append(l.unread[:i], l.unread[i+1:]...)
+			break
+		}
+	}
+	l.raw.Reset()
+	l.mutex.Unlock()
+
+	return parseEmulatorLog(lines)
+}
+
+// Pending returns the log lines that do not belong to a transaction with an id and removes them
+func (l *OverflowEmulatorLog) Pending() ([]OverflowEmulatorLogMessage, error) {
+	l.mutex.Lock()
+	lines := [][]byte{}
+	for _, executed := range l.executed {
+		lines = append(lines, executed...)
+	}
+	lines = append(lines, l.pending...)
+	l.executed = nil
+	l.pending = nil
+	l.raw.Reset()
+	l.mutex.Unlock()
+
+	return parseEmulatorLog(lines)
+}
+
+func (l *OverflowEmulatorLog) clearPending() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.pending = nil
+	l.executed = nil
+	l.raw.Reset()
+}
+
+// Reset removes everything from the log
+func (l *OverflowEmulatorLog) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.pending = nil
+	l.executed = nil
+	l.transactions = map[flow.Identifier][][]byte{}
+	l.submitted = map[flow.Identifier][][]byte{}
+	l.unread = nil
+	l.raw.Reset()
+}
+
+// Raw returns every line of the log since the log of the last interaction was read, this is the content of the deprecated OverflowState.Log
+func (l *OverflowEmulatorLog) Raw() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.raw.String()
+}
+
+type emulatorLogMarker struct {
+	log *OverflowEmulatorLog
+}
+
+func (m emulatorLogMarker) Write(p []byte) (int, error) {
+	if id, ok := logTransactionID(p); ok {
+		m.log.markExecuted(id)
+	}
+	return len(p), nil
+}
+
+type emulatorLogAdapter struct {
+	log *OverflowEmulatorLog
+}
+
+func (a emulatorLogAdapter) Write(p []byte) (int, error) {
+	id, ok := logTransactionID(p)
+	if !ok {
+		return len(p), nil
+	}
+	line := make([]byte, len(p))
+	copy(line, p)
+
+	a.log.mutex.Lock()
+	defer a.log.mutex.Unlock()
+	a.log.submitted[id] = append(a.log.submitted[id], line)
+	return len(p), nil
+}
+
+// the id of the transaction a json log line is about
+func logTransactionID(line []byte) (flow.Identifier, bool) {
+	var msg struct {
+		TxID string `json:"txID"`
+	}
+	if err := json.Unmarshal(line, &msg); err != nil || msg.TxID == "" {
+		return flow.EmptyID, false
+	}
+	return flow.HexToID(msg.TxID), true
+}
+
+func parseEmulatorLog(lines [][]byte) ([]OverflowEmulatorLogMessage, error) {
+	logMessage := []OverflowEmulatorLogMessage{}
+	for _, line := range lines {
+		var msg map[string]interface{}
+		err := json.Unmarshal(line, &msg)
+		if err != nil {
+			return []OverflowEmulatorLogMessage{}, err
+		}
+
+		doc := OverflowEmulatorLogMessage{}
+		doc.Msg, _ = msg["message"].(string)
+		doc.Level, _ = msg["level"].(string)
+
+		delete(msg, "message")
+		delete(msg, "level")
+		rawCom, ok := msg["computationUsed"]
+		if ok {
+			field, _ := rawCom.(float64)
+			doc.ComputationUsed = int(field)
+			delete(msg, "computationUsed")
+		}
+		doc.Fields = msg
+		logMessage = append(logMessage, doc)
+	}
+	return logMessage, nil
+}
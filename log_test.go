@@ -0,0 +1,133 @@
+package overflow
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentInteractions(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	logTx := `
+transaction(message: String) {
+	prepare(signer: &Account) {
+		log(message)
+	}
+}`
+
+	logScript := `
+access(all) fun main(message: String): String {
+	log(message)
+	return message
+}`
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		o.CreateAccount(fmt.Sprintf("concurrent%d", i))
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		message := fmt.Sprintf("message %d", i)
+		signer := fmt.Sprintf("concurrent%d", i)
+		go func() {
+			defer wg.Done()
+			res := o.Tx(logTx, WithSigner(signer), WithArg("message", message))
+			assert.NoError(t, res.Err)
+			assert.Equal(t, []string{fmt.Sprintf(`debug - Cadence log: "%s"`, message)}, res.EmulatorLog)
+			assert.NotZero(t, res.ComputationUsed)
+			assert.NotZero(t, res.Meter.LedgerInteractionUsed)
+		}()
+		go func() {
+			defer wg.Done()
+			res := o.Script(logScript, WithArg("message", message))
+			assert.NoError(t, res.Err)
+			if !assert.Len(t, res.Log, 1) {
+				return
+			}
+			assert.Equal(t, fmt.Sprintf(`Cadence log: "%s"`, message), res.Log[0].Msg)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEmulatorLog(t *testing.T) {
+	write := func(l *OverflowEmulatorLog, message string) {
+		_, _ = l.Write([]byte(fmt.Sprintf(`{"level":"debug","message":"%s"}`, message)))
+	}
+	mark := func(l *OverflowEmulatorLog, id string) {
+		_, _ = l.markerWriter().Write([]byte(fmt.Sprintf(`{"level":"debug","txID":"%s","message":"executed"}`, id)))
+	}
+	id1 := "0000000000000000000000000000000000000000000000000000000000000001"
+	id2 := "0000000000000000000000000000000000000000000000000000000000000002"
+
+	t.Run("Should split a block with many transactions", func(t *testing.T) {
+		l := newOverflowEmulatorLog()
+		write(l, "first")
+		write(l, executionDataMessage)
+		write(l, "second")
+		write(l, executionDataMessage)
+		mark(l, id1)
+		mark(l, id2)
+
+		second, err := l.Transaction(flow.HexToID(id2))
+		require.NoError(t, err)
+		require.Len(t, second, 2)
+		assert.Equal(t, "second", second[0].Msg)
+
+		first, err := l.Transaction(flow.HexToID(id1))
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		assert.Equal(t, "first", first[0].Msg)
+
+		again, err := l.Transaction(flow.HexToID(id1))
+		require.NoError(t, err)
+		assert.Empty(t, again)
+	})
+
+	t.Run("Should only keep adapter lines about a transaction", func(t *testing.T) {
+		l := newOverflowEmulatorLog()
+		_, _ = l.adapterWriter().Write([]byte(`{"level":"debug","message":"GetAccount called"}`))
+		_, _ = l.adapterWriter().Write([]byte(fmt.Sprintf(`{"level":"debug","txID":"%s","message":"Transaction submitted"}`, id2)))
+		write(l, "first")
+		write(l, executionDataMessage)
+		mark(l, id1)
+
+		first, err := l.Transaction(flow.HexToID(id1))
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		assert.Equal(t, "first", first[0].Msg)
+
+		write(l, "second")
+		write(l, executionDataMessage)
+		mark(l, id2)
+		second, err := l.Transaction(flow.HexToID(id2))
+		require.NoError(t, err)
+		require.Len(t, second, 3)
+		assert.Equal(t, "Transaction submitted", second[0].Msg)
+	})
+
+	t.Run("Should keep the raw lines for the deprecated log", func(t *testing.T) {
+		l := newOverflowEmulatorLog()
+		write(l, "script")
+		assert.Contains(t, l.Raw(), `"message":"script"`)
+		_, err := l.Pending()
+		require.NoError(t, err)
+		assert.Empty(t, l.Raw())
+	})
+
+	t.Run("Should return lines without transaction as pending", func(t *testing.T) {
+		l := newOverflowEmulatorLog()
+		write(l, "script")
+		pending, err := l.Pending()
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "script", pending[0].Msg)
+	})
+}
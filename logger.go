@@ -0,0 +1,93 @@
+package overflow
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Structured logging
+//
+// Lifecycle events in overflow are emitted as structured records to the logger set with WithLogger, by default they are discarded
+
+// the type of the attribute for what kind of interaction a record is about
+const (
+	interactionTransaction = "transaction"
+	interactionScript      = "script"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// the configured structured logger or one that discards everything
+func (o *OverflowState) structuredLogger() *slog.Logger {
+	if o.StructuredLogger == nil {
+		return discardLogger()
+	}
+	return o.StructuredLogger
+}
+
+// printOutput writes what overflow prints about an interaction to stdout, or to the logger as a single record if one is set
+func (o *OverflowState) printOutput(name string, print func(w io.Writer)) {
+	if o.StructuredLogger == nil {
+		print(os.Stdout)
+		return
+	}
+	var output strings.Builder
+	print(&output)
+	o.StructuredLogger.Info("output",
+		slog.String("network", o.Network.Name),
+		slog.String("name", name),
+		slog.String("output", strings.TrimSuffix(output.String(), "\n")),
+	)
+}
+
+func (o *OverflowState) logAccountCreated(name string, address string) {
+	o.structuredLogger().Info("account created",
+		slog.String("network", o.Network.Name),
+		slog.String("account", name),
+		slog.String("address", address),
+	)
+}
+
+func (o *OverflowState) logContractsDeployed(contracts []string, err error) {
+	if err != nil {
+		o.structuredLogger().Error("contract deployment failed",
+			slog.String("network", o.Network.Name),
+			slog.Any("error", err),
+		)
+		return
+	}
+	o.structuredLogger().Info("contracts deployed",
+		slog.String("network", o.Network.Name),
+		slog.Any("contracts", contracts),
+	)
+}
+
+func (o *OverflowState) logInteractionStarted(oib *OverflowInteractionBuilder, interactionType string) {
+	o.structuredLogger().LogAttrs(context.Background(), slog.LevelDebug, "interaction started", oib.logAttributes(interactionType)...)
+}
+
+// the result attributes are only sent in for transactions, scripts do not report an id or the computation they used
+func (o *OverflowState) logInteractionFinished(oib *OverflowInteractionBuilder, interactionType string, err error, result ...slog.Attr) {
+	attributes := append(oib.logAttributes(interactionType), result...)
+	if err != nil {
+		o.structuredLogger().LogAttrs(context.Background(), slog.LevelError, "interaction failed", append(attributes, slog.Any("error", err))...)
+		return
+	}
+	o.structuredLogger().LogAttrs(context.Background(), slog.LevelInfo, "interaction finished", attributes...)
+}
+
+func (oib *OverflowInteractionBuilder) logAttributes(interactionType string) []slog.Attr {
+	attributes := []slog.Attr{
+		slog.String("type", interactionType),
+		slog.String("name", oib.Name),
+	}
+	if oib.Proposer != nil {
+		attributes = append(attributes, slog.String("signer", oib.Overflow.logicalAccountName(oib.Proposer.Name)))
+	}
+	return attributes
+}
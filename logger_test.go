@@ -0,0 +1,80 @@
+package overflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	records := func() []map[string]interface{} {
+		result := []map[string]interface{}{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var record map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			result = append(result, record)
+		}
+		buf.Reset()
+		return result
+	}
+
+	o, err := OverflowTesting(WithLogger(logger))
+	require.NoError(t, err)
+
+	t.Run("Should log accounts and contracts on start", func(t *testing.T) {
+		messages := []string{}
+		for _, record := range records() {
+			messages = append(messages, record["msg"].(string))
+		}
+		assert.Contains(t, messages, "account created")
+		assert.Contains(t, messages, "contracts deployed")
+	})
+
+	t.Run("Should log transaction start and end", func(t *testing.T) {
+		res := o.Tx("arguments", WithSigner("first"), WithArg("test", "foo"))
+		require.NoError(t, res.Err)
+
+		logged := records()
+		require.Len(t, logged, 2)
+		assert.Equal(t, "interaction started", logged[0]["msg"])
+		assert.Equal(t, "DEBUG", logged[0]["level"])
+		assert.Equal(t, "interaction finished", logged[1]["msg"])
+		assert.Equal(t, "transaction", logged[1]["type"])
+		assert.Equal(t, "arguments", logged[1]["name"])
+		assert.Equal(t, "first", logged[1]["signer"])
+		assert.Equal(t, res.Id.String(), logged[1]["id"])
+		assert.Equal(t, float64(res.ComputationUsed), logged[1]["computation"])
+	})
+
+	t.Run("Should log failed script", func(t *testing.T) {
+		res := o.Script("test", WithArg("account", "not-an-account"))
+		require.Error(t, res.Err)
+
+		logged := records()
+		require.Len(t, logged, 2)
+		assert.Equal(t, "interaction failed", logged[1]["msg"])
+		assert.Equal(t, "ERROR", logged[1]["level"])
+		assert.Equal(t, "script", logged[1]["type"])
+		assert.Contains(t, logged[1]["error"], "not-an-account")
+		assert.NotContains(t, logged[1], "computation")
+	})
+
+	t.Run("Should log printed results instead of printing them", func(t *testing.T) {
+		res := o.Script("test", WithArg("account", "first"), WithPrintOptions())
+		require.NoError(t, res.Err)
+
+		logged := records()
+		require.Len(t, logged, 3)
+		assert.Equal(t, "output", logged[2]["msg"])
+		assert.Equal(t, "test", logged[2]["name"])
+		assert.Contains(t, logged[2]["output"], "Script test run result")
+	})
+}
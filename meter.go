@@ -9,6 +9,7 @@ type OverflowMeter struct {
 	LedgerInteractionUsed  int                                   `json:"ledgerInteractionUsed"`
 	ComputationUsed        int                                   `json:"computationUsed"`
 	MemoryUsed             int                                   `json:"memoryUsed"`
+	MemoryEstimate         int                                   `json:"memoryEstimate"`
 }
 
 // get the number of functions invocations
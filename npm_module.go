@@ -1,12 +1,16 @@
 package overflow
 
 import (
+	"bytes"
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 
 	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/parser"
 	"github.com/onflow/cadence/runtime/sema"
+	"golang.org/x/exp/slices"
 )
 
 // NPM Module
@@ -33,8 +37,73 @@ type OverflowAuthorizers [][]string
 // a type containing information about parameter types and orders
 type OverflowDeclarationInfo struct {
 	Parameters     map[string]string   `json:"parameters"`
-	Authorizers    OverflowAuthorizers `json:"-"`
+	Authorizers    OverflowAuthorizers `json:"authorizers"`
 	ParameterOrder []string            `json:"order"`
+	// the return type of a script, empty if it does not return anything
+	ReturnType string `json:"returnType,omitempty"`
+	// the logical name and networks from an #overflow pragma, see interactionTarget
+	Name     string   `json:"-"`
+	Networks []string `json:"-"`
+	// the doc comment of the transaction or main function, nil if there is none
+	Doc *OverflowDeclarationDoc `json:"doc,omitempty"`
+}
+
+// the human readable information in the doc comment of a transaction or script
+//
+// The first line is the title and the following lines the description.
+// A line like `@param amount: the amount to send` describes a parameter and any other `@tag text` line is a tag, like `@deprecated use send_v2`
+type OverflowDeclarationDoc struct {
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Parameters  map[string]string `json:"parameters,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+var (
+	docParamRegexp = regexp.MustCompile(`^@param\s+(\w+):?\s*(.*)$`)
+	docTagRegexp   = regexp.MustCompile(`^@(\w+)\s*(.*)$`)
+)
+
+// parse a doc comment, nil if it is empty
+func parseDeclarationDoc(docString string) *OverflowDeclarationDoc {
+	doc := &OverflowDeclarationDoc{Parameters: map[string]string{}, Tags: map[string]string{}}
+	description := []string{}
+	for _, line := range strings.Split(docString, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if match := docParamRegexp.FindStringSubmatch(line); match != nil {
+			doc.Parameters[match[1]] = match[2]
+			continue
+		}
+		if match := docTagRegexp.FindStringSubmatch(line); match != nil {
+			doc.Tags[match[1]] = match[2]
+			continue
+		}
+		if doc.Title == "" {
+			doc.Title = line
+			continue
+		}
+		description = append(description, line)
+	}
+	doc.Description = strings.TrimSpace(strings.Join(description, "\n"))
+	if doc.Title == "" && len(doc.Parameters) == 0 && len(doc.Tags) == 0 {
+		return nil
+	}
+	return doc
+}
+
+// the doc comment of the transaction or the main function of a script
+func declarationDoc(code []byte) *OverflowDeclarationDoc {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil
+	}
+	if transaction := program.SoleTransactionDeclaration(); transaction != nil {
+		return parseDeclarationDoc(transaction.DocString)
+	}
+	if main := sema.FunctionEntryPointDeclaration(program); main != nil {
+		return parseDeclarationDoc(main.DocString)
+	}
+	return nil
 }
 
 // a type representing one network in a solution, so mainnet/testnet/emulator
@@ -72,34 +141,12 @@ func (s *OverflowSolution) MergeSpecAndCode() *OverflowSolutionMerged {
 	}
 
 	for name, network := range s.Networks {
-
+		// conflicts are reported by ParseAll, the first interaction by name is used here
+		resolvedScripts, _ := resolveInteractions("script", s.Scripts, networkNames, name)
 		scripts := map[string]OverflowCodeWithSpec{}
-		for rawScriptName, code := range network.Scripts {
-
-			scriptName := rawScriptName
-
-			valid := true
-			for _, networkName := range networkNames {
-				overwriteNetworkScriptName := fmt.Sprintf("%s%s", networkName, scriptName)
-				_, ok := s.Scripts[overwriteNetworkScriptName]
-				if ok {
-					if networkName == name {
-						valid = false
-						break
-					}
-				}
-				if strings.HasPrefix(scriptName, networkName) {
-					if networkName == name {
-						scriptName = strings.TrimPrefix(scriptName, networkName)
-						valid = true
-						break
-					} else {
-						valid = false
-						break
-					}
-				}
-			}
-			if valid {
+		for scriptName, rawScriptName := range resolvedScripts {
+			code, ok := network.Scripts[rawScriptName]
+			if ok {
 				scripts[scriptName] = OverflowCodeWithSpec{
 					Code: formatCode(code),
 					Spec: s.Scripts[rawScriptName],
@@ -107,32 +154,11 @@ func (s *OverflowSolution) MergeSpecAndCode() *OverflowSolutionMerged {
 			}
 		}
 
+		resolvedTransactions, _ := resolveInteractions("transaction", s.Transactions, networkNames, name)
 		transactions := map[string]OverflowCodeWithSpec{}
-		for rawTxName, code := range network.Transactions {
-
-			txName := rawTxName
-			txValid := true
-			for _, networkName := range networkNames {
-				overwriteNetworkTxName := fmt.Sprintf("%s%s", networkName, txName)
-				_, ok := s.Transactions[overwriteNetworkTxName]
-				if ok {
-					if networkName == name {
-						txValid = false
-						break
-					}
-				}
-				if strings.HasPrefix(txName, networkName) {
-					if networkName == name {
-						txName = strings.TrimPrefix(txName, networkName)
-						txValid = true
-						break
-					} else {
-						txValid = false
-						break
-					}
-				}
-			}
-			if txValid {
+		for txName, rawTxName := range resolvedTransactions {
+			code, ok := network.Transactions[rawTxName]
+			if ok {
 				transactions[txName] = OverflowCodeWithSpec{
 					Code: formatCode(code),
 					Spec: s.Transactions[rawTxName],
@@ -150,33 +176,142 @@ func (s *OverflowSolution) MergeSpecAndCode() *OverflowSolutionMerged {
 	return &OverflowSolutionMerged{Networks: networks}
 }
 
-func declarationInfo(code []byte) *OverflowDeclarationInfo {
-	params, authorizerTypes := paramsAndAuthorizers(code)
-	if params == nil {
-		return &OverflowDeclarationInfo{
-			ParameterOrder: []string{},
-			Parameters:     map[string]string{},
-			Authorizers:    authorizerTypes,
+// the name of an interaction for a single network, admin/Mint is admin/mainnetMint on mainnet
+func withNetworkPrefix(name string, network string) string {
+	namespace, base := path.Split(name)
+	return namespace + network + base
+}
+
+// the name of a network specific interaction without the network, admin/mainnetMint is admin/Mint
+func withoutNetworkPrefix(name string, network string) (string, bool) {
+	namespace, base := path.Split(name)
+	if !strings.HasPrefix(base, network) {
+		return name, false
+	}
+	return namespace + strings.TrimPrefix(base, network), true
+}
+
+// the logical name and networks of an interaction, an #overflow pragma declares them explicitly
+//
+//	#overflow(name: "Foo", networks: ["emulator", "testnet"])
+//
+// without a pragma a name starting with a network like mainnetFoo is Foo on mainnet, other names are for all networks
+func interactionTarget(name string, info *OverflowDeclarationInfo, networks []string) (string, []string) {
+	if info != nil && (info.Name != "" || len(info.Networks) > 0) {
+		if info.Name != "" {
+			return info.Name, info.Networks
 		}
+		return name, info.Networks
 	}
-	parametersMap := make(map[string]string, len(params.Parameters))
-	var parameterList []string
-	for _, parameter := range params.Parameters {
-		parametersMap[parameter.Identifier.Identifier] = parameter.TypeAnnotation.Type.String()
-		parameterList = append(parameterList, parameter.Identifier.Identifier)
+	prefix := ""
+	for _, network := range networks {
+		if _, ok := withoutNetworkPrefix(name, network); ok && len(network) > len(prefix) {
+			prefix = network
+		}
+	}
+	if prefix == "" {
+		return name, nil
 	}
-	if len(parameterList) == 0 {
-		return &OverflowDeclarationInfo{
-			ParameterOrder: []string{},
-			Parameters:     map[string]string{},
-			Authorizers:    authorizerTypes,
+	strippedName, _ := withoutNetworkPrefix(name, prefix)
+	return strippedName, []string{prefix}
+}
+
+// the interaction to use for every logical name on a network, an interaction for the network replaces one for all networks
+// the error is the first pair of interactions that are used for the same name on the network
+func resolveInteractions(kind string, declarations map[string]*OverflowDeclarationInfo, networks []string, network string) (map[string]string, error) {
+	resolved := map[string]string{}
+	forNetwork := map[string]bool{}
+	var conflict error
+	for _, name := range sortedDeclarationNames(declarations) {
+		logicalName, targetNetworks := interactionTarget(name, declarations[name], networks)
+		if len(targetNetworks) > 0 && !slices.Contains(targetNetworks, network) {
+			continue
 		}
+		isForNetwork := len(targetNetworks) > 0
+		if existing, ok := resolved[logicalName]; ok {
+			if forNetwork[logicalName] == isForNetwork && conflict == nil {
+				conflict = fmt.Errorf("the %ss %s and %s are both %s on network %s", kind, existing, name, logicalName, network)
+			}
+			if forNetwork[logicalName] || !isForNetwork {
+				continue
+			}
+		}
+		resolved[logicalName] = name
+		forNetwork[logicalName] = isForNetwork
 	}
-	return &OverflowDeclarationInfo{
-		ParameterOrder: parameterList,
-		Parameters:     parametersMap,
-		Authorizers:    authorizerTypes,
+	return resolved, conflict
+}
+
+// the name and networks in the #overflow pragma of an interaction
+func interactionPragma(code []byte) (string, []string) {
+	if !bytes.Contains(code, []byte("#overflow")) {
+		return "", nil
+	}
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return "", nil
+	}
+	name := ""
+	var networks []string
+	for _, pragma := range program.PragmaDeclarations() {
+		invocation, ok := pragma.Expression.(*ast.InvocationExpression)
+		if !ok {
+			continue
+		}
+		identifier, ok := invocation.InvokedExpression.(*ast.IdentifierExpression)
+		if !ok || identifier.Identifier.Identifier != "overflow" {
+			continue
+		}
+		for _, argument := range invocation.Arguments {
+			switch value := argument.Expression.(type) {
+			case *ast.StringExpression:
+				if argument.Label == "name" {
+					name = value.Value
+				}
+			case *ast.ArrayExpression:
+				if argument.Label == "networks" {
+					for _, element := range value.Values {
+						if network, ok := element.(*ast.StringExpression); ok {
+							networks = append(networks, network.Value)
+						}
+					}
+				}
+			}
+		}
 	}
+	return name, networks
+}
+
+// an error if the #overflow pragma of an interaction has a network that is not in flow.json, so a misspelled network is not silently skipped
+func checkPragmaNetworks(kind string, name string, info *OverflowDeclarationInfo, networks []string) error {
+	for _, network := range info.Networks {
+		if !slices.Contains(networks, network) {
+			return fmt.Errorf("the #overflow pragma of %s %s has the network %s that is not in flow.json", kind, name, network)
+		}
+	}
+	return nil
+}
+
+func declarationInfo(code []byte) *OverflowDeclarationInfo {
+	name, networks := interactionPragma(code)
+	info := &OverflowDeclarationInfo{
+		ParameterOrder: []string{},
+		Parameters:     map[string]string{},
+		ReturnType:     returnType(code),
+		Name:           name,
+		Networks:       networks,
+		Doc:            declarationDoc(code),
+	}
+	params, authorizerTypes := paramsAndAuthorizers(code)
+	info.Authorizers = authorizerTypes
+	if params == nil {
+		return info
+	}
+	for _, parameter := range params.Parameters {
+		info.Parameters[parameter.Identifier.Identifier] = parameter.TypeAnnotation.Type.String()
+		info.ParameterOrder = append(info.ParameterOrder, parameter.Identifier.Identifier)
+	}
+	return info
 }
 
 func paramsAndAuthorizers(code []byte) (*ast.ParameterList, OverflowAuthorizers) {
@@ -226,6 +361,23 @@ func paramsAndAuthorizers(code []byte) (*ast.ParameterList, OverflowAuthorizers)
 	return nil, nil
 }
 
+// the return type of the main function of a script, empty for transactions and scripts that return Void
+func returnType(code []byte) string {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return ""
+	}
+	main := sema.FunctionEntryPointDeclaration(program)
+	if main == nil || main.ReturnTypeAnnotation == nil || main.ReturnTypeAnnotation.Type == nil {
+		return ""
+	}
+	name := main.ReturnTypeAnnotation.Type.String()
+	if name == "Void" {
+		return ""
+	}
+	return name
+}
+
 func formatCode(input string) string {
 	return strings.ReplaceAll(strings.TrimSpace(input), "\t", "    ")
 }
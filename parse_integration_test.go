@@ -2,6 +2,8 @@ package overflow
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hexops/autogold"
@@ -164,3 +166,118 @@ access(all) fun main(account: Address): String {
 		})
 	}
 }
+
+func TestParseNestedFolders(t *testing.T) {
+	o, err := OverflowTesting(
+		WithTransactionFolderName("testdata/nested/transactions"),
+		WithScriptFolderName("testdata/nested/scripts"),
+	)
+	require.NoError(t, err)
+
+	result, err := o.ParseAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin/mint", "user/mainnetmint", "user/mint", "user/testnetwork_status"}, sortedDeclarationNames(result.Transactions))
+	assert.Equal(t, []string{"admin/balance", "admin/balance_mainnet", "admin/emulator_only"}, sortedDeclarationNames(result.Scripts))
+
+	t.Run("doc comments", func(t *testing.T) {
+		assert.Equal(t, &OverflowDeclarationDoc{
+			Title:       "Mint flow tokens",
+			Description: "Mints new tokens with the token admin of the signer",
+			Parameters:  map[string]string{"recipient": "the account that receives the tokens", "amount": "the amount to mint"},
+			Tags:        map[string]string{"deprecated": "use a minter resource instead"},
+		}, result.Transactions["admin/mint"].Doc)
+		assert.Nil(t, result.Transactions["user/mint"].Doc)
+		assert.Equal(t, result.Transactions["admin/mint"].Doc, result.MergeSpecAndCode().Networks["emulator"].Transactions["admin/mint"].Spec.Doc)
+	})
+
+	t.Run("network prefix in a folder", func(t *testing.T) {
+		merged := result.MergeSpecAndCode()
+		assert.Contains(t, merged.Networks["mainnet"].Transactions["user/mint"].Code, `log("mainnet")`)
+		assert.Contains(t, merged.Networks["emulator"].Transactions["user/mint"].Code, "log(amount)")
+		assert.NotContains(t, merged.Networks["emulator"].Transactions, "user/mainnetmint")
+	})
+
+	t.Run("networks from pragmas", func(t *testing.T) {
+		merged := result.MergeSpecAndCode()
+		assert.Contains(t, merged.Networks["mainnet"].Scripts["admin/balance"].Code, "the mainnet balance")
+		assert.NotContains(t, merged.Networks["emulator"].Scripts["admin/balance"].Code, "the mainnet balance")
+		assert.NotContains(t, merged.Networks["mainnet"].Scripts, "admin/balance_mainnet")
+		assert.Contains(t, merged.Networks["emulator"].Scripts, "admin/emulator_only")
+		assert.NotContains(t, merged.Networks["testnet"].Scripts, "admin/emulator_only")
+		assert.Contains(t, merged.Networks["testnet"].Transactions, "user/testnetwork_status")
+	})
+
+	t.Run("run interactions for the network", func(t *testing.T) {
+		result := o.Script("admin/emulator_only")
+		assert.NoError(t, result.Err)
+		assert.Equal(t, "emulator", result.Output)
+
+		result = o.Script("admin/balance_mainnet", WithArg("account", "first"))
+		assert.ErrorContains(t, result.Err, "script admin/balance_mainnet is only for the networks mainnet and not emulator")
+	})
+
+	t.Run("misspelled network in a pragma", func(t *testing.T) {
+		folder, err := os.MkdirTemp("testdata", "pragma")
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(folder) })
+		require.NoError(t, os.WriteFile(filepath.Join(folder, "status.cdc"), []byte("access(all) fun main(): String {\n    return \"ok\"\n}\n"), 0o644))
+		ot, err := SetupTest([]OverflowOption{WithScriptFolderName(folder)}, func(o *OverflowState) error { return nil })
+		require.NoError(t, err)
+		assert.Equal(t, "ok", ot.O.Script("status").Output)
+
+		require.NoError(t, os.WriteFile(filepath.Join(folder, "typo.cdc"), []byte("#overflow(networks: [\"mainet\"])\naccess(all) fun main(): String {\n    return \"typo\"\n}\n"), 0o644))
+		require.NoError(t, ot.Reset())
+		assert.ErrorContains(t, ot.O.Script("status").Err, "the #overflow pragma of script typo has the network mainet that is not in flow.json")
+
+		_, err = ot.O.ParseAllWithConfig(true, []string{}, []string{})
+		assert.ErrorContains(t, err, "the #overflow pragma of script typo has the network mainet that is not in flow.json")
+	})
+
+	t.Run("interactions with the same name on a network", func(t *testing.T) {
+		declarations := map[string]*OverflowDeclarationInfo{
+			"mint":    {},
+			"mint_v2": {Name: "mint"},
+		}
+		_, err := resolveInteractions("transaction", declarations, []string{"emulator", "mainnet"}, "emulator")
+		assert.ErrorContains(t, err, "the transactions mint and mint_v2 are both mint on network emulator")
+
+		declarations["mint_v2"].Networks = []string{"mainnet"}
+		resolved, err := resolveInteractions("transaction", declarations, []string{"emulator", "mainnet"}, "mainnet")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"mint": "mint_v2"}, resolved)
+	})
+
+	t.Run("run namespaced interactions", func(t *testing.T) {
+		o.Tx("admin/mint",
+			WithSignerServiceAccount(),
+			WithArg("recipient", "first"),
+			WithArg("amount", 1.0),
+		).AssertSuccess(t)
+
+		result := o.Script("admin/balance", WithArg("account", "first"))
+		assert.NoError(t, result.Err)
+	})
+
+	t.Run("skip namespaced names", func(t *testing.T) {
+		names, err := interactionNames("transaction", "transactions", []string{"transactions/admin/mint.cdc", "transactions/user/mint.cdc"}, []string{"^admin/"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"transactions/user/mint.cdc": "user/mint"}, names)
+	})
+
+	t.Run("names that only differ in case", func(t *testing.T) {
+		_, err := interactionNames("transaction", "transactions", []string{"transactions/admin/Mint.cdc", "transactions/admin/mint.cdc"}, nil)
+		assert.ErrorContains(t, err, "the transaction names admin/Mint and admin/mint only differ in case")
+	})
+}
+
+func TestParseDeclarationDoc(t *testing.T) {
+	doc := parseDeclarationDoc(" Title\n\n Some description\n @param amount the amount\n @param to: the receiver\n @deprecated use send_v2")
+	assert.Equal(t, &OverflowDeclarationDoc{
+		Title:       "Title",
+		Description: "Some description",
+		Parameters:  map[string]string{"amount": "the amount", "to": "the receiver"},
+		Tags:        map[string]string{"deprecated": "use send_v2"},
+	}, doc)
+
+	assert.Nil(t, parseDeclarationDoc(" \n "))
+}
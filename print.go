@@ -2,6 +2,8 @@ package overflow
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/enescakir/emoji"
@@ -96,6 +98,11 @@ func WithArguments() OverflowPrinterOption {
 
 // print out an result
 func (o OverflowResult) Print(opbs ...OverflowPrinterOption) OverflowResult {
+	o.fprint(os.Stdout, opbs...)
+	return o
+}
+
+func (o OverflowResult) fprint(w io.Writer, opbs ...OverflowPrinterOption) {
 	printOpts := &OverflowPrinterBuilder{
 		Events:         true,
 		EventFilter:    OverflowEventFilter{},
@@ -132,18 +139,20 @@ func (o OverflowResult) Print(opbs ...OverflowPrinterOption) OverflowResult {
 
 	icon := emoji.OkHand.String()
 	if o.Err != nil {
-		color.Red("%v Error executing transaction: %s error:%v", emoji.PileOfPoo, o.Name, o.Err)
+		color.New(color.FgRed).Fprintf(w, "%v Error executing transaction: %s error:%v\n", emoji.PileOfPoo, o.Name, o.Err)
 		icon = emoji.PileOfPoo.String()
 	}
 
-	fmt.Printf("%v %s\n", icon, strings.Join(messages, " "))
+	fmt.Fprintf(w, "%v %s\n", icon, strings.Join(messages, " "))
 
 	if printOpts.TransactionUrl {
-		fmt.Printf("https://flowscan.org/transaction/%s\n", o.Id)
+		fmt.Fprintf(w, "https://flowscan.org/transaction/%s\n", o.Id)
 	}
 
 	if printOpts.Arguments {
-		o.PrintArguments(nil)
+		for _, line := range o.argumentLines() {
+			fmt.Fprintln(w, line)
+		}
 	}
 
 	if printOpts.Events {
@@ -152,14 +161,16 @@ func (o OverflowResult) Print(opbs ...OverflowPrinterOption) OverflowResult {
 			events = events.FilterEvents(printOpts.EventFilter)
 		}
 		if len(events) != 0 {
-			events.Print(nil)
+			for _, line := range events.printLines() {
+				fmt.Fprintln(w, line)
+			}
 		}
 	}
 
 	if printOpts.EmulatorLog && len(o.RawLog) > 0 {
-		fmt.Println("=== LOG ===")
+		fmt.Fprintln(w, "=== LOG ===")
 		for _, msg := range o.RawLog {
-			fmt.Println(msg.Msg)
+			fmt.Fprintln(w, msg.Msg)
 		}
 	}
 	/*
@@ -173,21 +184,20 @@ func (o OverflowResult) Print(opbs ...OverflowPrinterOption) OverflowResult {
 
 	if printOpts.Meter != 0 && o.Meter != nil {
 		if printOpts.Meter == 2 {
-			fmt.Println("=== METER ===")
-			fmt.Printf("LedgerInteractionUsed: %d\n", o.Meter.LedgerInteractionUsed)
+			fmt.Fprintln(w, "=== METER ===")
+			fmt.Fprintf(w, "LedgerInteractionUsed: %d\n", o.Meter.LedgerInteractionUsed)
 			if o.Meter.MemoryUsed != 0 {
-				fmt.Printf("Memory: %d\n", o.Meter.MemoryUsed)
+				fmt.Fprintf(w, "Memory: %d\n", o.Meter.MemoryUsed)
 				memories := strings.ReplaceAll(strings.Trim(fmt.Sprintf("%+v", o.Meter.MemoryIntensities), "map[]"), " ", "\n  ")
 
-				fmt.Println("Memory Intensities")
-				fmt.Printf(" %s\n", memories)
+				fmt.Fprintln(w, "Memory Intensities")
+				fmt.Fprintf(w, " %s\n", memories)
 			}
-			fmt.Printf("Computation: %d\n", o.Meter.ComputationUsed)
+			fmt.Fprintf(w, "Computation: %d\n", o.Meter.ComputationUsed)
 			intensities := strings.ReplaceAll(strings.Trim(fmt.Sprintf("%+v", o.Meter.ComputationIntensities), "map[]"), " ", "\n  ")
 
-			fmt.Println("Computation Intensities:")
-			fmt.Printf(" %s\n", intensities)
+			fmt.Fprintln(w, "Computation Intensities:")
+			fmt.Fprintf(w, " %s\n", intensities)
 		}
 	}
-	return o
 }
@@ -1,7 +1,9 @@
 package overflow
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 
@@ -64,10 +66,57 @@ type OverflowResult struct {
 	Arguments        CadenceArguments
 	UnderflowOptions underflow.Options
 	DeclarationInfo  OverflowDeclarationInfo
+
+	// The storage used and capacity of the authorizers and payer before and after the transaction if sent WithStorageTracking on the emulator
+	StorageUsage OverflowStorageUsages
+
+	// The overflow that sent the transaction, used to look up accounts in assertions
+	overflow *OverflowState
+}
+
+// MarshalJSON writes the result with the error as its message
+func (o OverflowResult) MarshalJSON() ([]byte, error) {
+	type result OverflowResult
+	var err string
+	if o.Err != nil {
+		err = o.Err.Error()
+	}
+	return json.Marshal(struct {
+		result
+		Err string `json:",omitempty"`
+	}{result: result(o), Err: err})
+}
+
+// the address of an account name from flow.json, addresses are returned as is
+func (o OverflowResult) accountAddress(account string) (string, error) {
+	if strings.HasPrefix(account, "0x") {
+		return account, nil
+	}
+	if o.overflow == nil {
+		return "", fmt.Errorf("can not find the address of account %s", account)
+	}
+	acct, err := o.overflow.AccountE(account)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%s", acct.Address.Hex()), nil
 }
 
 func (o OverflowResult) PrintArguments(t *testing.T) {
-	printOrLog(t, "=== Arguments ===")
+	for _, line := range o.argumentLines() {
+		printOrLog(t, line)
+	}
+}
+
+func (o OverflowResult) fprintArguments(w io.Writer) {
+	for _, line := range o.argumentLines() {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// the lines printed for the arguments
+func (o OverflowResult) argumentLines() []string {
+	lines := []string{"=== Arguments ==="}
 	maxLength := 0
 	for name := range o.Arguments {
 		if len(name) > maxLength {
@@ -82,8 +131,9 @@ func (o OverflowResult) PrintArguments(t *testing.T) {
 		if err != nil {
 			panic(err)
 		}
-		printOrLog(t, fmt.Sprintf(format, name, value))
+		lines = append(lines, fmt.Sprintf(format, name, value))
 	}
+	return lines
 }
 
 // Get a uint64 field with the given fieldname(most often an id) from an event with a given suffix
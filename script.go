@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"testing"
 
 	"github.com/bjartek/underflow"
@@ -52,10 +53,10 @@ func (o *OverflowState) Script(filename string, opts ...OverflowInteractionOptio
 	result := interaction.runScript()
 
 	if interaction.PrintOptions != nil && !interaction.NoLog {
-		result.Print()
+		o.printOutput(interaction.Name, result.fprint)
 	}
 	if o.StopOnError && result.Err != nil {
-		result.PrintArguments(nil)
+		o.printOutput(interaction.Name, result.fprintArguments)
 		panic(result.Err)
 	}
 	return result
@@ -64,6 +65,12 @@ func (o *OverflowState) Script(filename string, opts ...OverflowInteractionOptio
 func (fbi *OverflowInteractionBuilder) runScript() *OverflowScriptResult {
 	o := fbi.Overflow
 	osc := &OverflowScriptResult{Input: fbi}
+
+	o.logInteractionStarted(fbi, interactionScript)
+	defer func() {
+		o.logInteractionFinished(fbi, interactionScript, osc.Err)
+	}()
+
 	if fbi.Error != nil {
 		osc.Err = fbi.Error
 		return osc
@@ -71,7 +78,8 @@ func (fbi *OverflowInteractionBuilder) runScript() *OverflowScriptResult {
 
 	filePath := fmt.Sprintf("%s/%s.cdc", fbi.BasePath, fbi.FileName)
 
-	o.Log.Reset()
+	defer o.lockInteraction(true)()
+	o.EmulatorLog.clearPending()
 
 	script := flowkit.Script{
 		Code:     fbi.TransactionCode,
@@ -92,25 +100,11 @@ func (fbi *OverflowInteractionBuilder) runScript() *OverflowScriptResult {
 	}
 	//}
 
-	var logMessage []OverflowEmulatorLogMessage
-	dec := json.NewDecoder(o.Log)
-	for {
-		var doc OverflowEmulatorLogMessage
-
-		err := dec.Decode(&doc)
-		if err == io.EOF {
-			// all done
-			break
-		}
-		if err != nil {
-			osc.Err = err
-		}
-
-		logMessage = append(logMessage, doc)
+	logMessage, err := o.EmulatorLog.Pending()
+	if err != nil {
+		osc.Err = err
 	}
 
-	o.Log.Reset()
-
 	osc.Log = logMessage
 
 	return osc
@@ -121,11 +115,38 @@ type OverflowScriptResult struct {
 	Err    error
 	Result cadence.Value
 	Output interface{}
-	Input  *OverflowInteractionBuilder
+	Input  *OverflowInteractionBuilder `json:"-"`
 	Log    []OverflowEmulatorLogMessage
 }
 
+// MarshalJSON writes the result with the error as its message, the input is left out
+func (osr OverflowScriptResult) MarshalJSON() ([]byte, error) {
+	type result OverflowScriptResult
+	var err string
+	if osr.Err != nil {
+		err = osr.Err.Error()
+	}
+	return json.Marshal(struct {
+		result
+		Err string `json:",omitempty"`
+	}{result: result(osr), Err: err})
+}
+
 func (osr *OverflowScriptResult) PrintArguments(t *testing.T) {
+	for _, line := range osr.argumentLines() {
+		printOrLog(t, line)
+	}
+}
+
+func (osr *OverflowScriptResult) fprintArguments(w io.Writer) {
+	for _, line := range osr.argumentLines() {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// the lines printed for the arguments
+func (osr *OverflowScriptResult) argumentLines() []string {
+	lines := []string{}
 	args := osr.Input.NamedCadenceArguments
 	maxLength := 0
 	for name := range args {
@@ -141,8 +162,9 @@ func (osr *OverflowScriptResult) PrintArguments(t *testing.T) {
 		if err != nil {
 			panic(err)
 		}
-		printOrLog(t, fmt.Sprintf(format, name, value))
+		lines = append(lines, fmt.Sprintf(format, name, value))
 	}
+	return lines
 }
 
 // get the script as json
@@ -287,11 +309,15 @@ func (osr *OverflowScriptResult) AssertWant(t *testing.T, want autogold.Value) *
 
 // Print the result
 func (osr *OverflowScriptResult) Print() *OverflowScriptResult {
+	osr.fprint(os.Stdout)
+	return osr
+}
+
+func (osr *OverflowScriptResult) fprint(w io.Writer) {
 	json, err := osr.GetAsJson()
 	if err != nil {
-		color.Red(err.Error())
-		return osr
+		color.New(color.FgRed).Fprintln(w, err.Error())
+		return
 	}
-	fmt.Printf("%v Script %s run result:%v\n", emoji.Star, osr.Input.Name, json)
-	return osr
+	fmt.Fprintf(w, "%v Script %s run result:%v\n", emoji.Star, osr.Input.Name, json)
 }
@@ -40,6 +40,12 @@ return { "first" : {  "nested" : "nestedvalue"}}
 		mapScript().AssertWithPointerError(t, "/first/nested2", "Object has no key 'nested2'")
 	})
 
+	t.Run("Run the network specific script for a name", func(t *testing.T) {
+		result := o.Script("Foo", WithArg("account", "first"))
+		assert.NoError(t, result.Err)
+		assert.Equal(t, "emulatorFoo", result.Input.FileName)
+	})
+
 	t.Run("Get value using want", func(t *testing.T) {
 		mapScript().AssertWithPointerWant(t, "/first/nested", autogold.Want("nested", "nestedvalue"))
 	})
@@ -6,12 +6,11 @@
 package overflow
 
 import (
-	"bytes"
 	"context"
 	"embed"
 	"fmt"
-	"io"
 	"io/fs"
+	"log/slog"
 	"os"
 	"strconv"
 
@@ -19,6 +18,9 @@ import (
 	"github.com/onflow/cadence/runtime"
 	"github.com/onflow/flixkit-go/flixkit"
 	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/storage/remote"
+	"github.com/onflow/flow-emulator/storage/sqlite"
+	flowgo "github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flowkit/v2"
 	"github.com/onflow/flowkit/v2/config"
 	"github.com/onflow/flowkit/v2/gateway"
@@ -87,6 +89,7 @@ type OverflowBuilder struct {
 	Ctx                                 context.Context
 	ReaderWriter                        flowkit.ReaderWriter
 	Coverage                            *runtime.CoverageReport
+	StructuredLogger                    *slog.Logger
 	InputResolver                       *underflow.InputResolver
 	PrintOptions                        *[]OverflowPrinterOption
 	GlobalEventFilter                   OverflowEventFilter
@@ -96,10 +99,13 @@ type OverflowBuilder struct {
 	ScriptFolderName                    string
 	ServiceSuffix                       string
 	TransactionFolderName               string
+	FlixFolderName                      string
 	EmulatorOptions                     []emulator.Option
+	ForkOptions                         []remote.Option
 	GrpcDialOptions                     []grpc.DialOption
 	ConfigFiles                         []string
 	NewAccountFlowAmount                float64
+	ForkHeight                          uint64
 	GasLimit                            int
 	LogLevel                            int
 	UnderflowOptions                    underflow.Options
@@ -111,6 +117,13 @@ type OverflowBuilder struct {
 	FilterOutEmptyWithDrawDepositEvents bool
 	FilterOutFeeEvents                  bool
 	PrependNetworkName                  bool
+	Fork                                bool
+
+	// keep the state after SetupTest in a sqlite store so OverflowTest can start isolated environments from it
+	IsolatedEnvironments bool
+
+	// a folder with the sqlite store of the embedded emulator, an existing store in it is loaded
+	emulatorStoreDir string
 }
 
 func (o *OverflowBuilder) StartE() (*OverflowState, error) {
@@ -137,6 +150,14 @@ func (o *OverflowBuilder) StartResult() *OverflowState {
 		txPathName = o.TransactionFolderName
 	}
 
+	flixPathName := ""
+	if o.FlixFolderName != "" {
+		flixPathName = fmt.Sprintf("%s/%s", o.Path, o.FlixFolderName)
+		if o.Path == "" {
+			flixPathName = o.FlixFolderName
+		}
+	}
+
 	overflow := &OverflowState{
 		PrependNetworkToAccountNames:        o.PrependNetworkName,
 		ServiceAccountSuffix:                o.ServiceSuffix,
@@ -144,6 +165,7 @@ func (o *OverflowBuilder) StartResult() *OverflowState {
 		BasePath:                            o.Path,
 		TransactionBasePath:                 txPathName,
 		ScriptBasePath:                      scriptFolderName,
+		FlixBasePath:                        flixPathName,
 		FilterOutFeeEvents:                  o.FilterOutFeeEvents,
 		FilterOutEmptyWithDrawDepositEvents: o.FilterOutEmptyWithDrawDepositEvents,
 		GlobalEventFilter:                   o.GlobalEventFilter,
@@ -153,6 +175,7 @@ func (o *OverflowBuilder) StartResult() *OverflowState {
 		LogLevel:                            o.LogLevel,
 		CoverageReport:                      o.Coverage,
 		UnderflowOptions:                    o.UnderflowOptions,
+		StructuredLogger:                    o.StructuredLogger,
 	}
 
 	loader := o.ReaderWriter
@@ -188,27 +211,52 @@ func (o *OverflowBuilder) StartResult() *OverflowState {
 		network.Host = o.NetworkHost
 	}
 	overflow.Network = *network
+	overflow.GrpcDialOptions = o.GrpcDialOptions
 
 	logger := output.NewStdoutLogger(o.LogLevel)
 	overflow.Logger = logger
-	var memlog bytes.Buffer
-	overflow.Log = &memlog
+	overflow.EmulatorLog = newOverflowEmulatorLog()
+	overflow.Log = overflow.EmulatorLog.raw
 
 	if o.InMemory {
 		acc, _ := state.EmulatorServiceAccount()
 
-		// this is the emulator log
-		logWriter := io.Writer(&memlog)
-		emulatorLogger := zerolog.New(logWriter).Level(zerolog.DebugLevel)
+		// this is the emulator log, the server log is only used to correlate log lines with transactions
+		emulatorLogger := zerolog.New(overflow.EmulatorLog).Level(zerolog.DebugLevel)
+		serverLogger := zerolog.New(overflow.EmulatorLog.markerWriter()).Level(zerolog.DebugLevel)
+		adapterLogger := zerolog.New(overflow.EmulatorLog.adapterWriter()).Level(zerolog.DebugLevel)
 
+		// transactions sent from many goroutines can reference a block that is not the latest one
 		emulatorOptions := []emulator.Option{
 			emulator.WithLogger(emulatorLogger),
+			emulator.WithServerLogger(serverLogger),
+			emulator.WithTransactionExpiry(flowgo.DefaultTransactionExpiry),
 		}
 
 		if o.TransactionFees {
 			emulatorOptions = append(emulatorOptions, emulator.WithTransactionFeesEnabled(true), emulator.WithCoverageReport(o.Coverage))
 		}
 
+		if o.Fork {
+			forkOptions, err := o.forkEmulatorOptions(*network, &emulatorLogger)
+			if err != nil {
+				overflow.Error = err
+				return overflow
+			}
+			emulatorOptions = append(emulatorOptions, forkOptions...)
+		}
+
+		// the store is added before the options that are sent in so they can still set another store
+		if o.emulatorStoreDir != "" && !o.Fork {
+			store, err := sqlite.New(o.emulatorStoreDir)
+			if err != nil {
+				overflow.Error = errors.Wrapf(err, "could not create emulator store in %s", o.emulatorStoreDir)
+				return overflow
+			}
+			overflow.emulatorStore = store
+			emulatorOptions = append(emulatorOptions, emulator.WithStore(store))
+		}
+
 		emulatorOptions = append(emulatorOptions, o.EmulatorOptions...)
 
 		pk, _ := acc.Key.PrivateKey()
@@ -218,10 +266,16 @@ func (o *OverflowBuilder) StartResult() *OverflowState {
 			HashAlgo:  acc.Key.HashAlgo(),
 		}
 		gw := gateway.NewEmulatorGatewayWithOpts(emulatorKey,
-			gateway.WithLogger(&emulatorLogger),
+			gateway.WithLogger(&adapterLogger),
 			gateway.WithEmulatorOptions(emulatorOptions...),
 		)
+		blockchain, err := gatewayBlockchain(gw)
+		if err != nil {
+			overflow.Error = err
+			return overflow
+		}
 
+		overflow.emulator = blockchain
 		overflow.EmulatorGatway = gw
 		overflow.Flowkit = flowkit.NewFlowkit(state, *network, gw, logger)
 	} else {
@@ -343,6 +397,28 @@ func WithExistingEmulator() OverflowOption {
 	}
 }
 
+// WithFork will start the embedded emulator on top of the state of the given network at the given height, 0 means the latest sealed block
+// Registers are fetched lazily from the access node of that network, use WithNetworkHost to use another node then the one in flow.json
+// The network name is kept so that account names and contract aliases for that network resolve
+// Transaction validation is turned off so you can use WithImpersonatedSigner to sign as any address
+func WithFork(network string, height uint64) OverflowOption {
+	return func(o *OverflowBuilder) {
+		o.Network = network
+		o.Fork = true
+		o.ForkHeight = height
+		o.InMemory = true
+		o.DeployContracts = false
+		o.InitializeAccounts = false
+	}
+}
+
+// WithForkOption will send options to the remote store used in fork mode, useful to set the clients used to fetch registers
+func WithForkOption(opt ...remote.Option) OverflowOption {
+	return func(o *OverflowBuilder) {
+		o.ForkOptions = append(o.ForkOptions, opt...)
+	}
+}
+
 // DoNotPrependNetworkToAccountNames will not prepend the name of the network to account names
 func WithNoPrefixToAccountNames() OverflowOption {
 	return func(o *OverflowBuilder) {
@@ -371,6 +447,13 @@ func WithLogFull() OverflowOption {
 	}
 }
 
+// WithLogger will emit lifecycle events like account creation, contract deployment and interactions as structured records to the given logger
+func WithLogger(logger *slog.Logger) OverflowOption {
+	return func(o *OverflowBuilder) {
+		o.StructuredLogger = logger
+	}
+}
+
 // WithNoLog will not log anything from results or flowkit logger
 func WithLogNone() OverflowOption {
 	return func(o *OverflowBuilder) {
@@ -415,6 +498,13 @@ func WithTransactionFolderName(name string) OverflowOption {
 	}
 }
 
+// WithFlixFolderName sets a folder with FLIX templates, FlixTx("name") and FlixScript("name") use the file name.json in it before asking flixkit
+func WithFlixFolderName(name string) OverflowOption {
+	return func(o *OverflowBuilder) {
+		o.FlixFolderName = name
+	}
+}
+
 // WithTransactionFolderName will overwite the default script subdir for transactions `transactions`
 func WithFeesEvents() OverflowOption {
 	return func(o *OverflowBuilder) {
@@ -501,6 +591,26 @@ func WithCoverageReport() OverflowOption {
 	}
 }
 
+// store the state of the embedded emulator in a sqlite file in the given folder, if existing is set the state in it is loaded
+// and contracts and accounts are not created again
+func withEmulatorStore(dir string, existing bool) OverflowOption {
+	return func(o *OverflowBuilder) {
+		o.emulatorStoreDir = dir
+		if existing {
+			o.DeployContracts = false
+			o.InitializeAccounts = false
+		}
+	}
+}
+
+// WithIsolatedEnvironments makes SetupTest keep the state of the embedded emulator after setup in a sqlite store in a temporary folder
+// OverflowTest.Isolated and RunParallel start isolated environments from it, without this option the emulator runs in memory
+func WithIsolatedEnvironments() OverflowOption {
+	return func(o *OverflowBuilder) {
+		o.IsolatedEnvironments = true
+	}
+}
+
 func WithEmulatorOption(opt ...emulator.Option) OverflowOption {
 	return func(o *OverflowBuilder) {
 		o.EmulatorOptions = append(o.EmulatorOptions, opt...)
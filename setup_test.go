@@ -114,6 +114,12 @@ func TestOverflowv3(t *testing.T) {
 		assert.Equal(t, "tx", b.TransactionFolderName)
 	})
 
+	t.Run("WithFlixFolderName", func(t *testing.T) {
+		b := Apply(WithFlixFolderName("flix"))
+		assert.Equal(t, "flix", b.FlixFolderName)
+		assert.Equal(t, "./flix", b.StartResult().FlixBasePath)
+	})
+
 	t.Run("Overflow panics", func(t *testing.T) {
 		assert.Panics(t, func() {
 			Overflow(WithFlowConfig("nonexistant.json"))
@@ -0,0 +1,335 @@
+package overflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Solution diff
+//
+// Diff compares a merged solution with a newer version, for example the one in a published npm module with the current project, and lists
+// what changed for the release notes. Run it in CI before publishing to stop on breaking changes, cmd/solution-diff does this
+//
+//	func main() {
+//		o := overflow.Overflow(overflow.WithNetwork("embedded"))
+//		diff, err := o.DiffSolutionFile("npm/solution.json")
+//		...
+//		fmt.Print(diff)
+//		if len(diff.Breaking()) > 0 {
+//			os.Exit(1)
+//		}
+//	}
+
+// what changed between two versions of a solution
+type OverflowSolutionChangeKind string
+
+const (
+	// a network, interaction or contract is only in the newer solution
+	SolutionAdded OverflowSolutionChangeKind = "added"
+	// a network, interaction or contract is only in the older solution
+	SolutionRemoved OverflowSolutionChangeKind = "removed"
+	// the names, order or types of the parameters of an interaction changed
+	SolutionParametersChanged OverflowSolutionChangeKind = "parameters changed"
+	// the number of signers of a transaction or the entitlements they give changed
+	SolutionAuthorizersChanged OverflowSolutionChangeKind = "authorizers changed"
+	// the type a script returns changed
+	SolutionReturnTypeChanged OverflowSolutionChangeKind = "return type changed"
+	// the code of an interaction changed
+	SolutionCodeChanged OverflowSolutionChangeKind = "code changed"
+	// a contract has a new address
+	SolutionAddressChanged OverflowSolutionChangeKind = "address changed"
+)
+
+// a single change between two solutions
+type OverflowSolutionChange struct {
+	Network string
+	// network, script, transaction or contract
+	Type string
+	// the name of the interaction or contract, empty for a network
+	Name string
+	Kind OverflowSolutionChangeKind
+
+	// the parameters, authorizers, return type or address before and after the change
+	Before string
+	After  string
+
+	// unified diff of the code of an interaction
+	Diff string
+
+	// existing callers can break, the change removes something they use or changes its parameters, number of signers, return type or address
+	Breaking bool
+}
+
+// a human readable version of the change
+func (c OverflowSolutionChange) String() string {
+	subject := fmt.Sprintf("%s %s on %s", c.Type, c.Name, c.Network)
+	if c.Type == "network" {
+		subject = fmt.Sprintf("network %s", c.Network)
+	}
+	switch c.Kind {
+	case SolutionParametersChanged, SolutionAuthorizersChanged, SolutionReturnTypeChanged, SolutionAddressChanged:
+		return fmt.Sprintf("%s %s from %s to %s", subject, c.Kind, c.Before, c.After)
+	default:
+		return fmt.Sprintf("%s %s", subject, c.Kind)
+	}
+}
+
+// all changes between two solutions ordered by network, type and name
+type OverflowSolutionDiff struct {
+	Changes []OverflowSolutionChange
+}
+
+// the changes that can break existing callers
+func (d OverflowSolutionDiff) Breaking() []OverflowSolutionChange {
+	breaking := []OverflowSolutionChange{}
+	for _, change := range d.Changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// release notes with the breaking changes first
+func (d OverflowSolutionDiff) String() string {
+	if len(d.Changes) == 0 {
+		return "No changes\n"
+	}
+	var sb strings.Builder
+	for _, breaking := range []bool{true, false} {
+		changes := []OverflowSolutionChange{}
+		for _, change := range d.Changes {
+			if change.Breaking == breaking {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if breaking {
+			fmt.Fprintln(&sb, "Breaking changes")
+		} else {
+			fmt.Fprintln(&sb, "Changes")
+		}
+		for _, change := range changes {
+			fmt.Fprintf(&sb, "- %s\n", change)
+		}
+	}
+	return sb.String()
+}
+
+// the parameters of an interaction as a signature like (amount: UFix64, to: Address)
+func parameterSignature(spec *OverflowDeclarationInfo) string {
+	if spec == nil {
+		return "()"
+	}
+	parameters := []string{}
+	for _, name := range spec.ParameterOrder {
+		parameters = append(parameters, fmt.Sprintf("%s: %s", name, spec.Parameters[name]))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parameters, ", "))
+}
+
+// the signers of a transaction with the entitlements they give like [auth(BorrowValue) &Account, &Account]
+func authorizerSignature(spec *OverflowDeclarationInfo) string {
+	authorizers := []string{}
+	if spec != nil {
+		for _, entitlements := range spec.Authorizers {
+			if len(entitlements) == 0 {
+				authorizers = append(authorizers, "&Account")
+				continue
+			}
+			authorizers = append(authorizers, fmt.Sprintf("auth(%s) &Account", strings.Join(entitlements, ", ")))
+		}
+	}
+	return fmt.Sprintf("[%s]", strings.Join(authorizers, ", "))
+}
+
+func sortedKeys[V any](values ...map[string]V) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, m := range values {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func diffInteractions(network string, interactionType string, older map[string]OverflowCodeWithSpec, newer map[string]OverflowCodeWithSpec) ([]OverflowSolutionChange, error) {
+	changes := []OverflowSolutionChange{}
+	for _, name := range sortedKeys(older, newer) {
+		before, inOlder := older[name]
+		after, inNewer := newer[name]
+		change := OverflowSolutionChange{Network: network, Type: interactionType, Name: name}
+		switch {
+		case !inOlder:
+			change.Kind = SolutionAdded
+			changes = append(changes, change)
+			continue
+		case !inNewer:
+			change.Kind = SolutionRemoved
+			change.Breaking = true
+			changes = append(changes, change)
+			continue
+		}
+
+		if parameterSignature(before.Spec) != parameterSignature(after.Spec) {
+			change.Kind = SolutionParametersChanged
+			change.Before = parameterSignature(before.Spec)
+			change.After = parameterSignature(after.Spec)
+			change.Breaking = true
+			changes = append(changes, change)
+		}
+
+		// solutions written before authorizers and return types were part of the spec do not have them
+		if before.Spec != nil && before.Spec.Authorizers != nil && authorizerSignature(before.Spec) != authorizerSignature(after.Spec) {
+			changes = append(changes, OverflowSolutionChange{
+				Network:  network,
+				Type:     interactionType,
+				Name:     name,
+				Kind:     SolutionAuthorizersChanged,
+				Before:   authorizerSignature(before.Spec),
+				After:    authorizerSignature(after.Spec),
+				Breaking: after.Spec == nil || len(before.Spec.Authorizers) != len(after.Spec.Authorizers),
+			})
+		}
+
+		if before.Spec != nil && before.Spec.ReturnType != "" && after.Spec != nil && before.Spec.ReturnType != after.Spec.ReturnType {
+			returnType := after.Spec.ReturnType
+			if returnType == "" {
+				returnType = "Void"
+			}
+			changes = append(changes, OverflowSolutionChange{
+				Network:  network,
+				Type:     interactionType,
+				Name:     name,
+				Kind:     SolutionReturnTypeChanged,
+				Before:   before.Spec.ReturnType,
+				After:    returnType,
+				Breaking: true,
+			})
+		}
+
+		if before.Code != after.Code {
+			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(before.Code),
+				B:        difflib.SplitLines(after.Code),
+				FromFile: name,
+				ToFile:   name,
+				Context:  3,
+			})
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, OverflowSolutionChange{Network: network, Type: interactionType, Name: name, Kind: SolutionCodeChanged, Diff: diff})
+		}
+	}
+	return changes, nil
+}
+
+func diffContracts(network string, older *map[string]string, newer *map[string]string) []OverflowSolutionChange {
+	before := map[string]string{}
+	if older != nil {
+		before = *older
+	}
+	after := map[string]string{}
+	if newer != nil {
+		after = *newer
+	}
+
+	changes := []OverflowSolutionChange{}
+	for _, name := range sortedKeys(before, after) {
+		oldAddress, inOlder := before[name]
+		newAddress, inNewer := after[name]
+		change := OverflowSolutionChange{Network: network, Type: "contract", Name: name, Before: oldAddress, After: newAddress}
+		switch {
+		case !inOlder:
+			change.Kind = SolutionAdded
+		case !inNewer:
+			change.Kind = SolutionRemoved
+			change.Breaking = true
+		case oldAddress != newAddress:
+			// types and events of the contract get new identifiers
+			change.Kind = SolutionAddressChanged
+			change.Breaking = true
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Diff lists what changed from this solution to the newer one
+// Removed networks, interactions and contracts, changed parameters, return types, number of signers and contract addresses are breaking
+// Added ones, changed code and changed entitlements of the signers are not
+func (s *OverflowSolutionMerged) Diff(newer *OverflowSolutionMerged) (*OverflowSolutionDiff, error) {
+	diff := &OverflowSolutionDiff{Changes: []OverflowSolutionChange{}}
+	for _, network := range sortedKeys(s.Networks, newer.Networks) {
+		before, inOlder := s.Networks[network]
+		after, inNewer := newer.Networks[network]
+		switch {
+		case !inOlder:
+			diff.Changes = append(diff.Changes, OverflowSolutionChange{Network: network, Type: "network", Kind: SolutionAdded})
+			continue
+		case !inNewer:
+			diff.Changes = append(diff.Changes, OverflowSolutionChange{Network: network, Type: "network", Kind: SolutionRemoved, Breaking: true})
+			continue
+		}
+
+		diff.Changes = append(diff.Changes, diffContracts(network, before.Contracts, after.Contracts)...)
+		for _, interactions := range []struct {
+			interactionType string
+			older           map[string]OverflowCodeWithSpec
+			newer           map[string]OverflowCodeWithSpec
+		}{
+			{"script", before.Scripts, after.Scripts},
+			{"transaction", before.Transactions, after.Transactions},
+		} {
+			changes, err := diffInteractions(network, interactions.interactionType, interactions.older, interactions.newer)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not diff %ss on network %s", interactions.interactionType, network)
+			}
+			diff.Changes = append(diff.Changes, changes...)
+		}
+	}
+	return diff, nil
+}
+
+// ReadSolutionFile reads a merged solution that was written as json, like the one in a published npm module
+func ReadSolutionFile(file string) (*OverflowSolutionMerged, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	solution := &OverflowSolutionMerged{}
+	err = json.Unmarshal(content, solution)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read solution %s", file)
+	}
+	return solution, nil
+}
+
+// DiffSolutionFile lists what changed from the merged solution in the given json file to the solution of the current project
+func (o *OverflowState) DiffSolutionFile(file string) (*OverflowSolutionDiff, error) {
+	previous, err := ReadSolutionFile(file)
+	if err != nil {
+		return nil, err
+	}
+	solution, err := o.ParseAll()
+	if err != nil {
+		return nil, err
+	}
+	return previous.Diff(solution.MergeSpecAndCode())
+}
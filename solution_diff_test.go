@@ -0,0 +1,171 @@
+package overflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hexops/autogold"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolutionDiff(t *testing.T) {
+	mint := OverflowCodeWithSpec{
+		Code: "transaction(amount: UFix64) {}",
+		Spec: &OverflowDeclarationInfo{ParameterOrder: []string{"amount"}, Parameters: map[string]string{"amount": "UFix64"}},
+	}
+	balance := OverflowCodeWithSpec{
+		Code: "access(all) fun main(): UFix64 {\n    return 1.0\n}",
+		Spec: &OverflowDeclarationInfo{ParameterOrder: []string{}, Parameters: map[string]string{}},
+	}
+	older := &OverflowSolutionMerged{
+		Networks: map[string]OverflowSolutionMergedNetwork{
+			"emulator": {
+				Scripts:      map[string]OverflowCodeWithSpec{"balance": balance, "old": balance},
+				Transactions: map[string]OverflowCodeWithSpec{"mint": mint},
+				Contracts:    &map[string]string{"Debug": "0xf8d6e0586b0a20c7", "Old": "0xf8d6e0586b0a20c7"},
+			},
+			"testnet": {Scripts: map[string]OverflowCodeWithSpec{"balance": balance}},
+		},
+	}
+
+	newMint := OverflowCodeWithSpec{
+		Code: "transaction(amount: UFix64, to: Address) {}",
+		Spec: &OverflowDeclarationInfo{ParameterOrder: []string{"amount", "to"}, Parameters: map[string]string{"amount": "UFix64", "to": "Address"}},
+	}
+	newBalance := OverflowCodeWithSpec{
+		Code: "access(all) fun main(): UFix64 {\n    return 2.0\n}",
+		Spec: balance.Spec,
+	}
+	newer := &OverflowSolutionMerged{
+		Networks: map[string]OverflowSolutionMergedNetwork{
+			"emulator": {
+				Scripts:      map[string]OverflowCodeWithSpec{"balance": newBalance, "new": balance},
+				Transactions: map[string]OverflowCodeWithSpec{"mint": newMint},
+				Contracts:    &map[string]string{"Debug": "0x01cf0e2f2f715450", "New": "0xf8d6e0586b0a20c7"},
+			},
+			"mainnet": {Scripts: map[string]OverflowCodeWithSpec{"balance": balance}},
+		},
+	}
+
+	t.Run("changes", func(t *testing.T) {
+		diff, err := older.Diff(newer)
+		require.NoError(t, err)
+		autogold.Want("release notes", `Breaking changes
+- contract Debug on emulator address changed from 0xf8d6e0586b0a20c7 to 0x01cf0e2f2f715450
+- contract Old on emulator removed
+- script old on emulator removed
+- transaction mint on emulator parameters changed from (amount: UFix64) to (amount: UFix64, to: Address)
+- network testnet removed
+Changes
+- contract New on emulator added
+- script balance on emulator code changed
+- script new on emulator added
+- transaction mint on emulator code changed
+- network mainnet added
+`).Equal(t, diff.String())
+		assert.Len(t, diff.Breaking(), 5)
+
+		for _, change := range diff.Changes {
+			if change.Kind == SolutionCodeChanged && change.Name == "balance" {
+				assert.Contains(t, change.Diff, "-    return 1.0\n+    return 2.0\n")
+			}
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		diff, err := older.Diff(older)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Changes)
+		assert.Equal(t, "No changes\n", diff.String())
+	})
+
+	t.Run("parameter type changed", func(t *testing.T) {
+		changed := OverflowCodeWithSpec{
+			Code: mint.Code,
+			Spec: &OverflowDeclarationInfo{ParameterOrder: []string{"amount"}, Parameters: map[string]string{"amount": "UInt64"}},
+		}
+		changes, err := diffInteractions("emulator", "transaction", map[string]OverflowCodeWithSpec{"mint": mint}, map[string]OverflowCodeWithSpec{"mint": changed})
+		require.NoError(t, err)
+		assert.Equal(t, []OverflowSolutionChange{{
+			Network:  "emulator",
+			Type:     "transaction",
+			Name:     "mint",
+			Kind:     SolutionParametersChanged,
+			Before:   "(amount: UFix64)",
+			After:    "(amount: UInt64)",
+			Breaking: true,
+		}}, changes)
+	})
+
+	t.Run("authorizers changed", func(t *testing.T) {
+		signed := func(authorizers ...[]string) OverflowCodeWithSpec {
+			return OverflowCodeWithSpec{Code: mint.Code, Spec: &OverflowDeclarationInfo{ParameterOrder: mint.Spec.ParameterOrder, Parameters: mint.Spec.Parameters, Authorizers: authorizers}}
+		}
+		changes, err := diffInteractions("emulator", "transaction",
+			map[string]OverflowCodeWithSpec{"mint": signed([]string{"BorrowValue"}), "send": signed([]string{})},
+			map[string]OverflowCodeWithSpec{"mint": signed([]string{"BorrowValue", "SaveValue"}), "send": signed([]string{}, []string{})},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []OverflowSolutionChange{{
+			Network: "emulator",
+			Type:    "transaction",
+			Name:    "mint",
+			Kind:    SolutionAuthorizersChanged,
+			Before:  "[auth(BorrowValue) &Account]",
+			After:   "[auth(BorrowValue, SaveValue) &Account]",
+		}, {
+			Network:  "emulator",
+			Type:     "transaction",
+			Name:     "send",
+			Kind:     SolutionAuthorizersChanged,
+			Before:   "[&Account]",
+			After:    "[&Account, &Account]",
+			Breaking: true,
+		}}, changes)
+	})
+
+	t.Run("return type changed", func(t *testing.T) {
+		returning := func(returnType string) OverflowCodeWithSpec {
+			return OverflowCodeWithSpec{Code: balance.Code, Spec: &OverflowDeclarationInfo{ParameterOrder: []string{}, Parameters: map[string]string{}, ReturnType: returnType}}
+		}
+		changes, err := diffInteractions("emulator", "script",
+			map[string]OverflowCodeWithSpec{"balance": returning("UFix64"), "old": balance},
+			map[string]OverflowCodeWithSpec{"balance": returning("UInt64"), "old": returning("UFix64")},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []OverflowSolutionChange{{
+			Network:  "emulator",
+			Type:     "script",
+			Name:     "balance",
+			Kind:     SolutionReturnTypeChanged,
+			Before:   "UFix64",
+			After:    "UInt64",
+			Breaking: true,
+		}}, changes)
+	})
+}
+
+func TestDiffSolutionFile(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	solution, err := o.ParseAll()
+	require.NoError(t, err)
+	published := solution.MergeSpecAndCode()
+	delete(published.Networks["emulator"].Scripts, "block")
+
+	file := filepath.Join(t.TempDir(), "solution.json")
+	content, err := json.Marshal(published)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, content, 0o644))
+
+	diff, err := o.DiffSolutionFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, []OverflowSolutionChange{{Network: "emulator", Type: "script", Name: "block", Kind: SolutionAdded}}, diff.Changes)
+
+	_, err = o.DiffSolutionFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -3,14 +3,15 @@ package overflow
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/bjartek/underflow"
 	"github.com/enescakir/emoji"
@@ -22,6 +23,8 @@ import (
 	"github.com/onflow/cadence/runtime/interpreter"
 	"github.com/onflow/cadence/runtime/sema"
 	"github.com/onflow/flixkit-go/flixkit"
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/storage/sqlite"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flowkit/v2"
 	"github.com/onflow/flowkit/v2/accounts"
@@ -31,6 +34,7 @@ import (
 	"github.com/onflow/flowkit/v2/project"
 	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
+	"google.golang.org/grpc"
 )
 
 // Overflow client is an interface with the most used v1 api methods for overflow
@@ -101,6 +105,11 @@ type OverflowClient interface {
 var _ OverflowClient = (*OverflowState)(nil)
 
 // OverflowState contains information about how to Overflow is confitured and the current runnig state
+//
+// Interactions (Tx, Script, their FN variants and CreateAccount) are safe to run from many goroutines against the same state.
+// Transactions run in parallel and get their own emulator log by transaction id, scripts against the embedded emulator wait for running transactions and run one at the time since their log cannot be correlated.
+// Transactions that run at the same time need different proposers, or different proposal keys, since the sequence number is read when the transaction is built.
+// Changing the fields of the state or the flowkit State directly while interactions are running is not safe.
 type OverflowState struct {
 	State *flowkit.State
 	// the services from flowkit to performed operations on
@@ -108,6 +117,9 @@ type OverflowState struct {
 
 	EmulatorGatway *gateway.EmulatorGateway
 
+	// the blockchain of the embedded emulator, used to control time and blocks
+	emulator *emulator.Blockchain
+
 	ArchiveFlowkit *flowkit.Flowkit
 
 	// Configured variables that are taken from the builder since we need them in the execution of overflow later on
@@ -115,20 +127,49 @@ type OverflowState struct {
 	PrependNetworkToAccountNames bool
 	ServiceAccountSuffix         string
 	Gas                          int
+	GrpcDialOptions              []grpc.DialOption
 
 	// flowkit, emulator and emulator debug log uses three different logging technologies so we have them all stored here
 	// this flowkit Logger can go away when we can remove deprecations!
 	Logger   output.Logger
-	Log      *bytes.Buffer
 	LogLevel int
 
+	// lifecycle events are emitted as structured records to this logger, see WithLogger
+	StructuredLogger *slog.Logger
+
+	// the log of the embedded emulator split up per transaction
+	EmulatorLog *OverflowEmulatorLog
+
+	// Deprecated: use EmulatorLog, this is every line of the emulator log since the log of the last interaction was read.
+	// The buffer is reset whenever an interaction reads its log and is written to without synchronization, so it is unusable while interactions run concurrently.
+	// Use EmulatorLog.Raw to read it while holding the log mutex
+	Log *bytes.Buffer
+
+	// transactions hold this shared and scripts exclusive since the log of a script cannot be correlated
+	interactionMutex sync.RWMutex
+
+	// guards the accounts in State that can be added to at runtime
+	accountMutex sync.RWMutex
+
+	// the sqlite store of the embedded emulator if it was started with one, SetupTest uses it to snapshot the state after setup
+	emulatorStore *sqlite.Store
+
+	// the #overflow pragmas of the interactions in a folder, see interactionIndex
+	interactionIndexes    map[string]map[string]*OverflowDeclarationInfo
+	interactionIndexMutex sync.Mutex
+
+	// the clock of the embedded emulator when time is controlled, see SetBlockTime
+	clock *overflowClock
+
 	// If there was an error starting overflow it is stored here
 	Error error
 
 	// Paths that points to where .cdc files are stored and the posibilty to specify something besides the standard `transactions`/`scripts`subdirectories
+	// FlixBasePath is a folder with FLIX templates named after the interaction, it is empty if flix is only resolved by flixkit
 	BasePath            string
 	TransactionBasePath string
 	ScriptBasePath      string
+	FlixBasePath        string
 
 	// Filters to events to remove uneeded noise
 	FilterOutFeeEvents                  bool
@@ -196,31 +237,52 @@ func (o *OverflowState) QualifiedIdentifierFromSnakeCase(typeName string) (strin
 
 // account can either be a name from  accounts or the raw value
 func (o *OverflowState) QualifiedIdentifier(contract string, name string) (string, error) {
-	flowContract, err := o.State.Contracts().ByName(contract)
+	return o.qualifiedIdentifier(contract, name, o.Network)
+}
+
+// the qualified identifier of a type in a contract on the given network
+func (o *OverflowState) qualifiedIdentifier(contract string, name string, network config.Network) (string, error) {
+	address, err := o.contractAddress(contract, network)
 	if err != nil {
 		return "", err
 	}
+	return fmt.Sprintf("A.%s.%s.%s", address.String(), contract, name), nil
+}
+
+// the networks in flow.json sorted by name, generated code should not depend on the order they are read in
+func (o *OverflowState) sortedNetworks() []config.Network {
+	networks := append([]config.Network{}, *o.State.Networks()...)
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+	return networks
+}
+
+// the address of a contract on the given network from the aliases or deployments in flow.json
+func (o *OverflowState) contractAddress(contract string, network config.Network) (flow.Address, error) {
+	flowContract, err := o.State.Contracts().ByName(contract)
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
 
 	// we found the contract specified in contracts section
 	if flowContract != nil {
-		alias := flowContract.Aliases.ByNetwork(o.Network.Name)
+		alias := flowContract.Aliases.ByNetwork(network.Name)
 		if alias != nil {
-			return fmt.Sprintf("A.%s.%s.%s", alias.Address.String(), contract, name), nil
+			return alias.Address, nil
 		}
 	}
 
-	flowDeploymentContracts, err := o.State.DeploymentContractsByNetwork(o.Network)
+	flowDeploymentContracts, err := o.State.DeploymentContractsByNetwork(network)
 	if err != nil {
-		return "", err
+		return flow.EmptyAddress, err
 	}
 
 	for _, flowDeploymentContract := range flowDeploymentContracts {
 		if flowDeploymentContract.Name == contract {
-			return fmt.Sprintf("A.%s.%s.%s", flowDeploymentContract.AccountAddress, contract, name), nil
+			return flowDeploymentContract.AccountAddress, nil
 		}
 	}
 
-	return "", fmt.Errorf("you are trying to get the qualified identifier for something you are not creating or have mentioned in flow.json with name=%s", contract)
+	return flow.EmptyAddress, fmt.Errorf("you are trying to get the qualified identifier for something you are not creating or have mentioned in flow.json with name=%s", contract)
 }
 
 func (o *OverflowState) parseArguments(fileName string, code []byte, inputArgs map[string]interface{}) ([]cadence.Value, CadenceArguments, error) {
@@ -371,11 +433,9 @@ func (o *OverflowState) AccountPublicKey(name string) (string, error) {
 // AccountE fetch an account from State
 // Note that if `PrependNetworkToAccountNames` is specified it is prefixed with the network so that you can use the same logical name across networks
 func (o *OverflowState) AccountE(key string) (*accounts.Account, error) {
-	if o.PrependNetworkToAccountNames {
-		key = fmt.Sprintf("%s-%s", o.Network.Name, key)
-	}
-
-	account, err := o.State.Accounts().ByName(key)
+	o.accountMutex.RLock()
+	defer o.accountMutex.RUnlock()
+	account, err := o.State.Accounts().ByName(o.accountName(key))
 	if err != nil {
 		return nil, err
 	}
@@ -383,6 +443,22 @@ func (o *OverflowState) AccountE(key string) (*accounts.Account, error) {
 	return account, nil
 }
 
+// the name of the account in State for the given logical name
+func (o *OverflowState) accountName(key string) string {
+	if o.PrependNetworkToAccountNames {
+		return fmt.Sprintf("%s-%s", o.Network.Name, key)
+	}
+	return key
+}
+
+// the logical name of an account in State, the inverse of accountName
+func (o *OverflowState) logicalAccountName(name string) string {
+	if o.PrependNetworkToAccountNames {
+		return strings.TrimPrefix(name, o.Network.Name+"-")
+	}
+	return name
+}
+
 // return the address of an given account
 func (o *OverflowState) Address(key string) string {
 	return fmt.Sprintf("0x%s", o.FlowAddress(key))
@@ -475,6 +551,7 @@ func (o *OverflowState) CreateAccountsE(ctx context.Context) (*OverflowState, er
 		if account.Address.Hex() != newA.Address.Hex() {
 			return nil, fmt.Errorf("the configured address for this account is %s but the created one is %s, consider reordering addresses in flow.json", account.Address.Hex(), newA.Address.Hex())
 		}
+		o.logAccountCreated(o.logicalAccountName(account.Name), newA.Address.HexWithPrefix())
 
 		messages := []string{
 			fmt.Sprintf("%v", emoji.Person),
@@ -485,15 +562,15 @@ func (o *OverflowState) CreateAccountsE(ctx context.Context) (*OverflowState, er
 		}
 
 		if o.Network.Name == "emulator" && o.NewUserFlowAmount != 0.0 {
-			res := o.MintFlowTokens(account.Address.String(), o.NewUserFlowAmount)
-			if res.Error != nil {
+			err := o.mintFlowTokens(account.Address.String(), o.NewUserFlowAmount)
+			if err != nil {
 				return nil, errors.Wrap(err, "could not mint flow tokens")
 			}
 			messages = append(messages, "with flow:", fmt.Sprintf("%.2f", o.NewUserFlowAmount))
 		}
 
 		if o.PrintOptions != nil && o.LogLevel == output.NoneLog {
-			fmt.Println(strings.Join(messages, " "))
+			o.printOutput(account.Name, func(w io.Writer) { fmt.Fprintln(w, strings.Join(messages, " ")) })
 		}
 	}
 	return o, nil
@@ -501,10 +578,16 @@ func (o *OverflowState) CreateAccountsE(ctx context.Context) (*OverflowState, er
 
 // InitializeContracts installs all contracts in the deployment block for the configured network
 func (o *OverflowState) InitializeContracts(ctx context.Context) *OverflowState {
-	o.Log.Reset()
+	defer o.lockInteraction(true)()
+	o.EmulatorLog.Reset()
 	contracts, err := o.Flowkit.DeployProject(ctx, flowkit.UpdateExistingContract(true))
+	names := []string{}
+	for _, c := range contracts {
+		names = append(names, c.Name)
+	}
+	o.logContractsDeployed(names, err)
 	if err != nil {
-		log, _ := o.readLog()
+		log, _ := o.EmulatorLog.Pending()
 		if len(log) != 0 {
 			messages := []string{}
 			for _, msg := range log {
@@ -519,17 +602,27 @@ func (o *OverflowState) InitializeContracts(ctx context.Context) *OverflowState
 	} else {
 		// we do not have log output from emulator but we want to print results
 		if o.LogLevel == output.NoneLog && o.PrintOptions != nil {
-			names := []string{}
-			for _, c := range contracts {
-				names = append(names, c.Name)
-			}
-			fmt.Printf("%v deploy contracts %s\n", emoji.Scroll, strings.Join(names, ", "))
+			o.printOutput("deploy contracts", func(w io.Writer) { fmt.Fprintf(w, "%v deploy contracts %s\n", emoji.Scroll, strings.Join(names, ", ")) })
 		}
 	}
-	o.Log.Reset()
+	o.EmulatorLog.Reset()
 	return o
 }
 
+// lockInteraction locks the embedded emulator for an interaction and returns the function to unlock it
+// exclusive access is needed when the emulator log is read without a transaction id
+func (o *OverflowState) lockInteraction(exclusive bool) func() {
+	if o.EmulatorGatway == nil {
+		return func() {}
+	}
+	if exclusive {
+		o.interactionMutex.Lock()
+		return o.interactionMutex.Unlock
+	}
+	o.interactionMutex.RLock()
+	return o.interactionMutex.RUnlock
+}
+
 // GetAccount takes the account name  and returns the state of that account on the given network.
 func (o *OverflowState) GetAccount(ctx context.Context, key string) (*flow.Account, error) {
 	account, err := o.AccountE(key)
@@ -540,39 +633,6 @@ func (o *OverflowState) GetAccount(ctx context.Context, key string) (*flow.Accou
 	return o.Flowkit.GetAccount(ctx, rawAddress)
 }
 
-func (o OverflowState) readLog() ([]OverflowEmulatorLogMessage, error) {
-	var logMessage []OverflowEmulatorLogMessage
-	dec := json.NewDecoder(o.Log)
-	for {
-		var msg map[string]interface{}
-
-		err := dec.Decode(&msg)
-		if err == io.EOF {
-			// all done
-			break
-		}
-
-		if err != nil {
-			return []OverflowEmulatorLogMessage{}, err
-		}
-		doc := OverflowEmulatorLogMessage{Msg: msg["message"].(string), Level: msg["level"].(string)}
-
-		delete(msg, "message")
-		delete(msg, "level")
-		rawCom, ok := msg["computationUsed"]
-		if ok {
-			field := rawCom.(float64)
-			doc.ComputationUsed = int(field)
-			delete(msg, "computationUsed")
-		}
-		doc.Fields = msg
-		logMessage = append(logMessage, doc)
-	}
-
-	o.Log.Reset()
-	return logMessage, nil
-}
-
 // If you store this in a struct and add arguments to it it will not reset between calls
 func (o *OverflowState) TxFN(outerOpts ...OverflowInteractionOption) OverflowTransactionFunction {
 	return func(filename string, opts ...OverflowInteractionOption) *OverflowResult {
@@ -602,10 +662,10 @@ func (o *OverflowState) sendTx(ftb *OverflowInteractionBuilder) *OverflowResult
 
 	if ftb.PrintOptions != nil && !ftb.NoLog {
 		po := *ftb.PrintOptions
-		result.Print(po...)
+		o.printOutput(ftb.Name, func(w io.Writer) { result.fprint(w, po...) })
 	}
 	if o.StopOnError && result.Err != nil {
-		result.PrintArguments(nil)
+		o.printOutput(ftb.Name, result.fprintArguments)
 		panic(result.Err)
 	}
 
@@ -693,12 +753,12 @@ func (o *OverflowState) BuildInteraction(filename string, interactionType string
 
 	// we need to do flix stuff
 	if interactionType == "flix" {
-		flix, err := o.Flixkit.GetTemplateAndReplaceImports(ftb.Ctx, filename, o.Network.Name)
+		cadence, err := o.flixCadence(ftb.Ctx, filename)
 		if err != nil {
 			ftb.Error = errors.Wrapf(err, "failed getting flix using query %s for network %s", filename, o.Network.Name)
 			return ftb
 		}
-		ftb.TransactionCode = []byte(flix.Cadence)
+		ftb.TransactionCode = []byte(cadence)
 		ftb.FileName = filename
 	} else if strings.Contains(filename, "transaction (") ||
 		strings.Contains(filename, "transaction {") ||
@@ -710,10 +770,19 @@ func (o *OverflowState) BuildInteraction(filename string, interactionType string
 		ftb.TransactionCode = []byte(filename)
 		ftb.FileName = "inline"
 	} else {
-		filePath := fmt.Sprintf("%s/%s.cdc", ftb.BasePath, filename)
+		otherPath := o.ScriptBasePath
+		if interactionType == "script" {
+			otherPath = o.TransactionBasePath
+		}
+		fileName, err := o.interactionFileName(interactionType, ftb.BasePath, otherPath, filename)
+		if err != nil {
+			ftb.Error = err
+			return ftb
+		}
+		filePath := fmt.Sprintf("%s/%s.cdc", ftb.BasePath, fileName)
 		code, err := ftb.getContractCode(filePath)
 		ftb.TransactionCode = code
-		ftb.FileName = filename
+		ftb.FileName = fileName
 		if ftb.Name == "" {
 			ftb.Name = filename
 		} else {
@@ -743,48 +812,18 @@ func (o *OverflowState) ParseAll() (*OverflowSolution, error) {
 // Parse the gieven overflow state with filters
 func (o *OverflowState) ParseAllWithConfig(skipContracts bool, txSkip []string, scriptSkip []string) (*OverflowSolution, error) {
 	warnings := []string{}
-	transactions := map[string]string{}
-	err := filepath.Walk(fmt.Sprintf("%s/transactions/", o.BasePath), func(path string, info os.FileInfo, _ error) error {
-		if strings.HasSuffix(path, ".cdc") {
-			name := strings.TrimSuffix(info.Name(), ".cdc")
-			for _, txSkip := range txSkip {
-				match, err := regexp.MatchString(txSkip, name)
-				if err != nil {
-					return err
-				}
-				if match {
-					return nil
-				}
-			}
-
-			transactions[path] = name
-		}
-		return nil
-	})
+	transactions, err := o.interactionFiles("transaction", o.TransactionBasePath, o.ScriptBasePath, txSkip)
 	if err != nil {
 		return nil, err
 	}
-	scripts := map[string]string{}
-	err = filepath.Walk(fmt.Sprintf("%s/scripts/", o.BasePath), func(path string, info os.FileInfo, _ error) error {
-		if strings.HasSuffix(path, ".cdc") {
-			name := strings.TrimSuffix(info.Name(), ".cdc")
-			for _, scriptSkip := range txSkip {
-				match, err := regexp.MatchString(scriptSkip, name)
-				if err != nil {
-					return err
-				}
-				if match {
-					return nil
-				}
-			}
-			scripts[path] = name
-		}
-		return nil
-	})
+	scripts, err := o.interactionFiles("script", o.ScriptBasePath, o.TransactionBasePath, scriptSkip)
 	if err != nil {
 		return nil, err
 	}
 
+	networks := o.State.Networks()
+	networkNames := o.networkNames()
+
 	transactionDeclarations := map[string]*OverflowDeclarationInfo{}
 	for path, name := range transactions {
 		code, err := o.State.ReaderWriter().ReadFile(path)
@@ -793,6 +832,10 @@ func (o *OverflowState) ParseAllWithConfig(skipContracts bool, txSkip []string,
 		}
 		info := declarationInfo(code)
 		if info != nil {
+			err = checkPragmaNetworks("transaction", name, info, networkNames)
+			if err != nil {
+				return nil, err
+			}
 			transactionDeclarations[name] = info
 		}
 	}
@@ -805,11 +848,25 @@ func (o *OverflowState) ParseAllWithConfig(skipContracts bool, txSkip []string,
 		}
 		info := declarationInfo(code)
 		if info != nil {
+			err = checkPragmaNetworks("script", name, info, networkNames)
+			if err != nil {
+				return nil, err
+			}
 			scriptDeclarations[name] = info
 		}
 	}
 
-	networks := o.State.Networks()
+	for _, networkName := range networkNames {
+		_, err := resolveInteractions("transaction", transactionDeclarations, networkNames, networkName)
+		if err != nil {
+			return nil, err
+		}
+		_, err = resolveInteractions("script", scriptDeclarations, networkNames, networkName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	solutionNetworks := map[string]*OverflowSolutionNetwork{}
 	for _, nw := range *networks {
 
@@ -865,7 +922,139 @@ func (o *OverflowState) ParseAllWithConfig(skipContracts bool, txSkip []string,
 	}, nil
 }
 
-func (o *OverflowState) contracts(network config.Network) (map[string]string, error) {
+// the .cdc files in a folder and its subfolders, the other interaction folder is skipped if it is inside this folder
+func walkInteractionFolder(folder string, otherFolder string) ([]string, error) {
+	paths := []string{}
+	err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		// a folder that does not exist has no interactions
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() && path != folder && filepath.Clean(path) == filepath.Clean(otherFolder) {
+			return filepath.SkipDir
+		}
+		if strings.HasSuffix(path, ".cdc") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// the names of interaction files keyed by path, a name is the path in the folder without .cdc like admin/mint
+// files with a name matching one of the skip expressions are left out
+func interactionNames(kind string, folder string, paths []string, skip []string) (map[string]string, error) {
+	names := map[string]string{}
+	// names that only differ in case would overwrite each other on case insensitive file systems
+	lowerCaseNames := map[string]string{}
+	for _, path := range paths {
+		relative, err := filepath.Rel(folder, path)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.ToSlash(strings.TrimSuffix(relative, ".cdc"))
+
+		skipped := false
+		for _, expression := range skip {
+			match, err := regexp.MatchString(expression, name)
+			if err != nil {
+				return nil, err
+			}
+			skipped = skipped || match
+		}
+		if skipped {
+			continue
+		}
+
+		if other, ok := lowerCaseNames[strings.ToLower(name)]; ok {
+			return nil, fmt.Errorf("the %s names %s and %s only differ in case", kind, other, name)
+		}
+		lowerCaseNames[strings.ToLower(name)] = name
+		names[path] = name
+	}
+	return names, nil
+}
+
+// the transactions or scripts in a folder keyed by path with their namespaced name
+func (o *OverflowState) interactionFiles(kind string, folder string, otherFolder string, skip []string) (map[string]string, error) {
+	paths, err := walkInteractionFolder(folder, otherFolder)
+	if err != nil {
+		return nil, err
+	}
+	return interactionNames(kind, folder, paths, skip)
+}
+
+// the #overflow pragmas of the interactions in a folder by name, a folder is only read the first time it is used
+func (o *OverflowState) interactionIndex(kind string, folder string, otherFolder string) (map[string]*OverflowDeclarationInfo, error) {
+	o.interactionIndexMutex.Lock()
+	defer o.interactionIndexMutex.Unlock()
+	if index, ok := o.interactionIndexes[folder]; ok {
+		return index, nil
+	}
+
+	files, err := o.interactionFiles(kind, folder, otherFolder, []string{})
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]*OverflowDeclarationInfo{}
+	for path, name := range files {
+		code, err := o.State.ReaderWriter().ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read file at path %s", path)
+		}
+		pragmaName, networks := interactionPragma(code)
+		info := &OverflowDeclarationInfo{Name: pragmaName, Networks: networks}
+		err = checkPragmaNetworks(kind, name, info, o.networkNames())
+		if err != nil {
+			return nil, err
+		}
+		index[name] = info
+	}
+	if o.interactionIndexes == nil {
+		o.interactionIndexes = map[string]map[string]*OverflowDeclarationInfo{}
+	}
+	o.interactionIndexes[folder] = index
+	return index, nil
+}
+
+// forget the #overflow pragmas read from the interaction folders, so files that are added or changed are read again
+func (o *OverflowState) clearInteractionIndexes() {
+	o.interactionIndexMutex.Lock()
+	defer o.interactionIndexMutex.Unlock()
+	o.interactionIndexes = nil
+}
+
+// the names of the networks in flow.json
+func (o *OverflowState) networkNames() []string {
+	names := []string{}
+	for _, network := range *o.State.Networks() {
+		names = append(names, network.Name)
+	}
+	return names
+}
+
+// the file name of an interaction on the network overflow is connected to
+// a name is resolved like in the npm module with #overflow pragmas and network prefixes, names that are not found are used as they are
+func (o *OverflowState) interactionFileName(kind string, folder string, otherFolder string, name string) (string, error) {
+	index, err := o.interactionIndex(kind, folder, otherFolder)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolveInteractions(kind, index, o.networkNames(), o.Network.Name)
+	if err != nil {
+		return "", err
+	}
+	if fileName, ok := resolved[name]; ok {
+		return fileName, nil
+	}
+	if info, ok := index[name]; ok && len(info.Networks) > 0 && !slices.Contains(info.Networks, o.Network.Name) {
+		return "", fmt.Errorf("%s %s is only for the networks %s and not %s", kind, name, strings.Join(info.Networks, ", "), o.Network.Name)
+	}
+	return name, nil
+}
+
+// the contracts in the deployment for the network sorted so that dependencies come first
+func (o *OverflowState) sortedDeploymentContracts(network config.Network) ([]*project.Contract, error) {
 	contracts, err := o.State.DeploymentContractsByNetwork(network)
 	if err != nil {
 		return nil, err
@@ -876,7 +1065,11 @@ func (o *OverflowState) contracts(network config.Network) (map[string]string, er
 		return nil, err
 	}
 
-	sorted, err := deployment.Sort()
+	return deployment.Sort()
+}
+
+func (o *OverflowState) contracts(network config.Network) (map[string]string, error) {
+	sorted, err := o.sortedDeploymentContracts(network)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,141 @@
+package overflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// Storage usage
+//
+// When a transaction is sent WithStorageTracking on the embedded emulator the storage used and capacity of the authorizers and payer is read before and after
+
+// the storage of an account before and after a transaction in bytes
+type OverflowStorageUsage struct {
+	UsedBefore     uint64
+	UsedAfter      uint64
+	CapacityBefore uint64
+	CapacityAfter  uint64
+}
+
+// the number of bytes the transaction added to the storage of the account, negative if storage was freed
+func (s OverflowStorageUsage) Increase() int64 {
+	return int64(s.UsedAfter) - int64(s.UsedBefore)
+}
+
+// the storage usage for each address in a transaction
+type OverflowStorageUsages map[string]OverflowStorageUsage
+
+// a human readable version of the usage sorted by address
+func (su OverflowStorageUsages) String() string {
+	lines := []string{}
+	for address, usage := range su {
+		lines = append(lines, fmt.Sprintf("%s used %d -> %d (%+d) capacity %d -> %d", address, usage.UsedBefore, usage.UsedAfter, usage.Increase(), usage.CapacityBefore, usage.CapacityAfter))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+const storageUsageScript = `
+access(all) fun main(addresses: [Address]): {Address: [UInt64]} {
+	let usage: {Address: [UInt64]} = {}
+	for address in addresses {
+		let account = getAccount(address)
+		usage[address] = [account.storage.used, account.storage.capacity]
+	}
+	return usage
+}
+`
+
+// the storage used and capacity of the given addresses
+// the caller must hold the exclusive interaction lock since the emulator log is cleared
+func (o *OverflowState) storageUsage(ctx context.Context, addresses []flow.Address) (map[string][2]uint64, error) {
+	args := []cadence.Value{}
+	for _, address := range addresses {
+		args = append(args, cadence.NewAddress(address))
+	}
+	value, err := o.Flowkit.ExecuteScript(ctx, flowkit.Script{
+		Code: []byte(storageUsageScript),
+		Args: []cadence.Value{cadence.NewArray(args)},
+	}, flowkit.ScriptQuery{Latest: true})
+	o.EmulatorLog.clearPending()
+	if err != nil {
+		return nil, err
+	}
+
+	dictionary, ok := value.(cadence.Dictionary)
+	if !ok {
+		return nil, fmt.Errorf("storage usage script returned %s and not a dictionary", value)
+	}
+	usage := map[string][2]uint64{}
+	for _, pair := range dictionary.Pairs {
+		address, ok := pair.Key.(cadence.Address)
+		if !ok {
+			return nil, fmt.Errorf("storage usage script returned invalid address %s", pair.Key)
+		}
+		values, ok := pair.Value.(cadence.Array)
+		if !ok || len(values.Values) != 2 {
+			return nil, fmt.Errorf("storage usage script returned invalid usage %s", pair.Value)
+		}
+		used, _ := values.Values[0].(cadence.UInt64)
+		capacity, _ := values.Values[1].(cadence.UInt64)
+		usage[fmt.Sprintf("0x%s", address.Hex())] = [2]uint64{uint64(used), uint64(capacity)}
+	}
+	return usage, nil
+}
+
+// combine the usage before and after a transaction
+func storageUsages(before map[string][2]uint64, after map[string][2]uint64) OverflowStorageUsages {
+	usages := OverflowStorageUsages{}
+	for address, usage := range before {
+		usages[address] = OverflowStorageUsage{
+			UsedBefore:     usage[0],
+			CapacityBefore: usage[1],
+			UsedAfter:      after[address][0],
+			CapacityAfter:  after[address][1],
+		}
+	}
+	return usages
+}
+
+// the unique addresses of the authorizers and payer of a transaction
+func storageTrackedAddresses(authorizers []flow.Address, payer flow.Address) []flow.Address {
+	seen := map[flow.Address]bool{}
+	addresses := []flow.Address{}
+	for _, address := range append(authorizers, payer) {
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// Assert that the transaction increased the storage used by the account with less than the given number of bytes
+// The account can be a name from flow.json or an address, the transaction must be sent WithStorageTracking
+func (o OverflowResult) AssertStorageIncreaseLessThan(t *testing.T, account string, bytes int64) OverflowResult {
+	t.Helper()
+	address, err := o.accountAddress(account)
+	if err != nil {
+		assert.Fail(t, err.Error())
+		return o
+	}
+
+	usage, ok := o.StorageUsage[address]
+	if !ok {
+		assert.Fail(t, fmt.Sprintf("no storage usage for %s, send the transaction WithStorageTracking and make sure the account is an authorizer or payer", account))
+		return o
+	}
+	if !assert.Less(t, usage.Increase(), bytes, "storage increase of %s", account) {
+		printOrLog(t, "=== Storage usage ===")
+		printOrLog(t, o.StorageUsage.String())
+	}
+	return o
+}
@@ -0,0 +1,44 @@
+package overflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageUsage(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	saveString := `
+transaction(value: String) {
+	prepare(signer: auth(SaveValue) &Account) {
+		signer.storage.save(value, to: /storage/overflowStorageTest)
+	}
+}`
+
+	t.Run("Should track storage of authorizers and payer", func(t *testing.T) {
+		res := o.Tx(saveString,
+			WithProposer("second"),
+			WithPayloadSigner("first"),
+			WithArg("value", cadence.String(strings.Repeat("a", 2000))),
+			WithStorageTracking(),
+		).AssertSuccess(t).
+			AssertStorageIncreaseLessThan(t, "first", 3000).
+			AssertStorageIncreaseLessThan(t, "second", 1)
+
+		require.Len(t, res.StorageUsage, 2)
+		usage := res.StorageUsage[o.Address("first")]
+		assert.Greater(t, usage.Increase(), int64(2000))
+		assert.NotZero(t, usage.CapacityAfter)
+		assert.Zero(t, res.StorageUsage[o.Address("second")].Increase())
+	})
+
+	t.Run("Should not track storage by default", func(t *testing.T) {
+		res := o.Tx("sendFlow", WithSigner("first"), WithArg("amount", 1.0), WithArg("to", "second")).AssertSuccess(t)
+		assert.Empty(t, res.StorageUsage)
+	})
+}
@@ -2,7 +2,11 @@ package overflow
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
 )
 
 // Templates
@@ -51,7 +55,7 @@ func (o *OverflowState) UploadImageAsDataUrl(filename string, accountName string
 	return o.UploadString(content, accountName)
 }
 
-// UploadString will upload the given string data in 1mb chunkts to /storage/upload of the given account
+// UploadString will upload the given string data in chunks to /storage/upload of the given account
 func (o *OverflowState) UploadString(content string, accountName string) error {
 	// unload previous content if any.
 	res := o.Tx(`
@@ -88,6 +92,209 @@ func (o *OverflowState) UploadString(content string, accountName string) error {
 	return nil
 }
 
+// Storage blobs
+//
+// Content is uploaded in chunks to a storage path as a String or as [UInt8] for binary data. An upload that stopped halfway resumes from the content
+// already stored when it is started again with the same content, and the SHA3-256 hash of the stored content is checked after the upload and the download
+//
+//	err := o.UploadBytesToPath(image, "first", "/storage/art")
+//	image, err := o.DownloadBytesFromPath("first", "/storage/art")
+
+const (
+	// the number of bytes sent in one transaction, a String is loaded and saved again for every chunk so the computation of a chunk grows with what is
+	// already stored and a String of more than about 200kB does not fit in the computation limit, larger content is uploaded as [UInt8]
+	uploadStringChunkSize = 50_000
+	uploadBytesChunkSize  = 100_000
+	// the number of bytes read in one script
+	downloadChunkSize = 100_000
+)
+
+// the type, length in bytes and hash of the content at a storage path, the type is empty if nothing is stored and the length and hash are only set for a String or [UInt8]
+type storedBlob struct {
+	Type   string `json:"type"`
+	Length int    `json:"length"`
+	Hash   string `json:"hash"`
+}
+
+func (o *OverflowState) storedBlob(accountName string, path string) (*storedBlob, error) {
+	result := o.Script(`
+access(all) fun main(address: Address, path: StoragePath): {String: AnyStruct} {
+	let account = getAuthAccount<auth(BorrowValue) &Account>(address)
+	let stored = account.storage.type(at: path)
+	var bytes: [UInt8] = []
+	if stored == Type<String>() {
+		bytes = (*account.storage.borrow<&String>(from: path)!).utf8
+	} else if stored == Type<[UInt8]>() {
+		bytes = *account.storage.borrow<&[UInt8]>(from: path)!
+	} else if stored == nil {
+		return {}
+	} else {
+		return {"type": stored!.identifier}
+	}
+	return {"type": stored!.identifier, "length": UInt64(bytes.length), "hash": String.encodeHex(HashAlgorithm.SHA3_256.hash(bytes))}
+}
+`, WithArg("address", accountName), WithArg("path", path), WithoutLog())
+	if result.Err != nil {
+		return nil, errors.Wrapf(result.Err, "could not read the content at %s", path)
+	}
+	blob := &storedBlob{}
+	err := result.MarshalAs(blob)
+	if err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// the valid utf8 prefix of a string that is at most the given number of bytes long
+func utf8Prefix(content string, size int) string {
+	if size >= len(content) {
+		return content
+	}
+	for size > 0 && !utf8.RuneStart(content[size]) {
+		size--
+	}
+	return content[:size]
+}
+
+// upload content in chunks to a storage path, blobType is String or [UInt8]
+func (o *OverflowState) uploadBlob(content string, blobType string, accountName string, path string) error {
+	stored, err := o.storedBlob(accountName, path)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	switch {
+	case stored.Type == "":
+	case stored.Type == blobType && stored.Length <= len(content) && stored.Hash == sha3Hex(content[:stored.Length]):
+		// the content is the start of what is uploaded, the upload stopped halfway
+		offset = stored.Length
+	default:
+		res := o.Tx(`
+transaction(path: StoragePath) {
+	prepare(signer: auth(Storage) &Account) {
+		let stored = signer.storage.type(at: path)
+		if stored != Type<String>() && stored != Type<[UInt8]>() {
+			panic("there is no uploaded content at ".concat(path.toString()))
+		}
+		signer.storage.load<AnyStruct>(from: path)
+	}
+}
+`, WithSigner(accountName), WithArg("path", path), WithoutLog())
+		if res.Err != nil {
+			return errors.Wrapf(res.Err, "could not remove the content at %s", path)
+		}
+		stored.Type = ""
+	}
+
+	for offset < len(content) || stored.Type == "" {
+		var res *OverflowResult
+		var part string
+		if blobType == "String" {
+			part = utf8Prefix(content[offset:], uploadStringChunkSize)
+			if part == "" && offset < len(content) {
+				return fmt.Errorf("could not split the content for %s into chunks", path)
+			}
+			res = o.Tx(`
+transaction(path: StoragePath, part: String) {
+	prepare(signer: auth(Storage) &Account) {
+		let existing = signer.storage.load<String>(from: path) ?? ""
+		signer.storage.save(existing.concat(part), to: path)
+	}
+}
+`, WithSigner(accountName), WithArg("path", path), WithArg("part", cadenceString(part)), WithoutLog())
+		} else {
+			part = content[offset:min(offset+uploadBytesChunkSize, len(content))]
+			res = o.Tx(`
+transaction(path: StoragePath, part: String) {
+	prepare(signer: auth(Storage) &Account) {
+		let bytes = part.decodeHex()
+		if let existing = signer.storage.borrow<auth(Mutate) &[UInt8]>(from: path) {
+			existing.appendAll(bytes)
+			return
+		}
+		signer.storage.save(bytes, to: path)
+	}
+}
+`, WithSigner(accountName), WithArg("path", path), WithArg("part", cadenceString(hex.EncodeToString([]byte(part)))), WithoutLog())
+		}
+		if res.Err != nil {
+			return errors.Wrapf(res.Err, "upload to %s stopped at byte %d of %d, upload the same content again to resume", path, offset, len(content))
+		}
+		offset += len(part)
+		stored.Type = blobType
+	}
+
+	uploaded, err := o.storedBlob(accountName, path)
+	if err != nil {
+		return err
+	}
+	if uploaded.Type != blobType || uploaded.Hash != sha3Hex(content) {
+		return fmt.Errorf("the content at %s has hash %s and not the hash %s of the uploaded content", path, uploaded.Hash, sha3Hex(content))
+	}
+	return nil
+}
+
+// UploadStringToPath uploads a string in chunks to the given storage path of the account, an upload that stopped halfway resumes
+func (o *OverflowState) UploadStringToPath(content string, accountName string, path string) error {
+	return o.uploadBlob(content, "String", accountName, path)
+}
+
+// UploadBytesToPath uploads binary data in chunks to the given storage path of the account as [UInt8], an upload that stopped halfway resumes
+func (o *OverflowState) UploadBytesToPath(content []byte, accountName string, path string) error {
+	return o.uploadBlob(string(content), "[UInt8]", accountName, path)
+}
+
+// DownloadBytesFromPath reads the String or [UInt8] at the given storage path of the account in chunks and checks its hash
+func (o *OverflowState) DownloadBytesFromPath(accountName string, path string) ([]byte, error) {
+	stored, err := o.storedBlob(accountName, path)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Type != "String" && stored.Type != "[UInt8]" {
+		return nil, fmt.Errorf("there is no String or [UInt8] at %s", path)
+	}
+
+	content := make([]byte, 0, stored.Length)
+	for len(content) < stored.Length {
+		result := o.Script(`
+access(all) fun main(address: Address, path: StoragePath, from: Int, upTo: Int): String {
+	let account = getAuthAccount<auth(BorrowValue) &Account>(address)
+	let stored = account.storage.type(at: path)
+	if stored == Type<String>() {
+		return String.encodeHex((*account.storage.borrow<&String>(from: path)!).utf8.slice(from: from, upTo: upTo))
+	}
+	if stored == Type<[UInt8]>() {
+		return String.encodeHex(account.storage.borrow<&[UInt8]>(from: path)!.slice(from: from, upTo: upTo))
+	}
+	panic("there is no String or [UInt8] at ".concat(path.toString()))
+}
+`, WithArg("address", accountName), WithArg("path", path), WithArg("from", len(content)), WithArg("upTo", min(len(content)+downloadChunkSize, stored.Length)), WithoutLog())
+		if result.Err != nil {
+			return nil, errors.Wrapf(result.Err, "could not download %s", path)
+		}
+		part, err := hex.DecodeString(fmt.Sprintf("%v", result.Output))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not download %s", path)
+		}
+		content = append(content, part...)
+	}
+
+	if sha3Hex(string(content)) != stored.Hash {
+		return nil, fmt.Errorf("the downloaded content of %s has hash %s and not %s", path, sha3Hex(string(content)), stored.Hash)
+	}
+	return content, nil
+}
+
+// DownloadStringFromPath reads the content at the given storage path of the account as a string
+func (o *OverflowState) DownloadStringFromPath(accountName string, path string) (string, error) {
+	content, err := o.DownloadBytesFromPath(accountName, path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
 // Get the free capacity in an account
 func (o *OverflowState) GetFreeCapacity(accountName string) int {
 	result := o.Script(`
@@ -105,9 +312,16 @@ access(all) fun main(user:Address): UInt64{
 }
 
 func (o *OverflowState) MintFlowTokens(accountName string, amount float64) *OverflowState {
+	err := o.mintFlowTokens(accountName, amount)
+	if err != nil {
+		o.Error = err
+	}
+	return o
+}
+
+func (o *OverflowState) mintFlowTokens(accountName string, amount float64) error {
 	if o.Network.Name != "emulator" {
-		o.Error = fmt.Errorf("can only mint new flow on emulator")
-		return o
+		return fmt.Errorf("can only mint new flow on emulator")
 	}
 	result := o.Tx(`
 import FungibleToken from 0xee82856bf20e2aa6
@@ -143,10 +357,7 @@ transaction(recipient: Address, amount: UFix64) {
 		WithoutLog(),
 	)
 
-	if result.Err != nil {
-		o.Error = result.Err
-	}
-	return o
+	return result.Err
 }
 
 // A method to fill up a users storage, useful when testing
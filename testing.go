@@ -2,23 +2,49 @@ package overflow
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
-	"github.com/onflow/flow-go/utils/io"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
 type OverflowTest struct {
 	O      *OverflowState
 	height uint64
+
+	// the accounts registered after setup, they are restored on Reset
+	accounts accounts.Accounts
+
+	// used to start isolated environments from a snapshot of the emulator store after setup, see Isolated
+	opts     []OverflowOption
+	dir      string
+	snapshot string
+
+	// isolated environments that are not in use
+	mutex sync.Mutex
+	pool  []*OverflowTest
+	// all isolated environments ever started, used to merge coverage
+	isolated []*OverflowTest
 }
 
 func (ot *OverflowTest) Reset() error {
+	err := ot.O.resetClock()
+	if err != nil {
+		return err
+	}
 	block, err := ot.O.GetLatestBlock(context.Background())
 	if err != nil {
 		return err
 	}
+	ot.O.restoreAccounts(ot.accounts)
+	ot.O.clearInteractionIndexes()
 	height := block.Height
 	if ot.height != height {
 		return ot.O.RollbackToBlockHeight(ot.height)
@@ -26,6 +52,20 @@ func (ot *OverflowTest) Reset() error {
 	return nil
 }
 
+// a copy of the accounts registered in State
+func (o *OverflowState) registeredAccounts() accounts.Accounts {
+	o.accountMutex.RLock()
+	defer o.accountMutex.RUnlock()
+	return append(accounts.Accounts{}, *o.State.Accounts()...)
+}
+
+// replace the accounts registered in State, accounts created at runtime after the copy was made are removed
+func (o *OverflowState) restoreAccounts(registered accounts.Accounts) {
+	o.accountMutex.Lock()
+	defer o.accountMutex.Unlock()
+	*o.State.Accounts() = append(accounts.Accounts{}, registered...)
+}
+
 func (ot *OverflowTest) Run(t *testing.T, name string, f func(t *testing.T)) {
 	t.Helper()
 	err := ot.Reset()
@@ -35,40 +75,194 @@ func (ot *OverflowTest) Run(t *testing.T, name string, f func(t *testing.T)) {
 	require.NoError(t, err)
 }
 
-func (ot *OverflowTest) Teardown() {
+// Isolated returns an OverflowState with the same accounts and contracts as after setup that only the given test uses
+// Use it in tests that call t.Parallel, the state is rolled back and reused by another test when the test is done
+// Environments are taken from a pool and a new emulator is started from a snapshot of the state after setup if the pool is empty
+func (ot *OverflowTest) Isolated(t *testing.T) *OverflowState {
+	t.Helper()
+	env, err := ot.checkout()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := env.Reset()
+		if err != nil {
+			t.Errorf("could not reset isolated overflow: %v", err)
+			return
+		}
+		ot.checkin(env)
+	})
+	return env.O
+}
 
-	report := ot.O.GetCoverageReport()
-	if report == nil {
-		return
+// RunParallel runs f as a parallel subtest with its own isolated OverflowState, see Isolated
+func (ot *OverflowTest) RunParallel(t *testing.T, name string, f func(t *testing.T, o *OverflowState)) {
+	t.Helper()
+	t.Run(name, func(t *testing.T) {
+		t.Parallel()
+		f(t, ot.Isolated(t))
+	})
+}
+
+func (ot *OverflowTest) checkout() (*OverflowTest, error) {
+	ot.mutex.Lock()
+	if len(ot.pool) > 0 {
+		env := ot.pool[len(ot.pool)-1]
+		ot.pool = ot.pool[:len(ot.pool)-1]
+		ot.mutex.Unlock()
+		return env, nil
 	}
+	ot.mutex.Unlock()
 
-	bytes, err := json.MarshalIndent(report, "", "  ")
+	env, err := ot.restore()
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	ot.mutex.Lock()
+	ot.isolated = append(ot.isolated, env)
+	ot.mutex.Unlock()
+	return env, nil
+}
+
+// start a new emulator from the snapshot of the store after setup with the same registered accounts
+func (ot *OverflowTest) restore() (env *OverflowTest, err error) {
+	if ot.snapshot == "" {
+		return nil, fmt.Errorf("isolated environments need SetupTest with WithIsolatedEnvironments on the embedded emulator without fork")
 	}
-	err = io.WriteFile("coverage-report.json", bytes)
+	dir, err := os.MkdirTemp(ot.dir, "isolated")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(dir)
+		}
+	}()
+	err = copyFile(ot.snapshot, filepath.Join(dir, "emulator.sqlite"))
+	if err != nil {
+		return nil, err
+	}
+
+	allOpts := []OverflowOption{WithNetwork("testing")}
+	allOpts = append(allOpts, ot.opts...)
+	allOpts = append(allOpts, withEmulatorStore(dir, true))
+	o := Overflow(allOpts...)
+	if o.Error != nil {
+		o.closeEmulatorStore()
+		return nil, o.Error
+	}
+	o.restoreAccounts(ot.accounts)
+	return &OverflowTest{O: o, height: ot.height, accounts: ot.accounts}, nil
+}
+
+func copyFile(from string, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	target, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(target, source)
+	if err != nil {
+		target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+func (ot *OverflowTest) checkin(env *OverflowTest) {
+	ot.mutex.Lock()
+	defer ot.mutex.Unlock()
+	ot.pool = append(ot.pool, env)
+}
+
+// Teardown writes the coverage report, including coverage from isolated environments, if coverage is enabled
+// It panics if the report could not be written or the coverage is below a threshold, use TeardownE to handle the error
+func (ot *OverflowTest) Teardown(opts ...OverflowCoverageOption) {
+	err := ot.TeardownE(opts...)
 	if err != nil {
 		panic(err)
 	}
+}
+
+// TeardownE writes the coverage report, including coverage from isolated environments, if coverage is enabled
+// An error is returned if the coverage is below a threshold given in opts
+// The emulator stores of the test are closed and their files removed
+func (ot *OverflowTest) TeardownE(opts ...OverflowCoverageOption) error {
+	defer ot.removeEmulatorStores()
+	if ot.O.GetCoverageReport() == nil {
+		return nil
+	}
+
+	report := runtime.NewCoverageReport()
+	MergeCoverageReport(report, ot.O.GetCoverageReport())
+	ot.mutex.Lock()
+	for _, env := range ot.isolated {
+		if envReport := env.O.GetCoverageReport(); envReport != nil && envReport != ot.O.GetCoverageReport() {
+			MergeCoverageReport(report, envReport)
+		}
+	}
+	ot.mutex.Unlock()
 
+	return ot.O.writeCoverageReport(report, opts)
 }
 
-func SetupTest(opts []OverflowOption, setup func(o *OverflowState) error) (*OverflowTest, error) {
+// close the emulator stores of the test and the isolated environments before their folder is removed
+func (ot *OverflowTest) removeEmulatorStores() {
+	if ot.dir == "" {
+		return
+	}
+	ot.mutex.Lock()
+	for _, env := range ot.isolated {
+		env.O.closeEmulatorStore()
+	}
+	ot.mutex.Unlock()
+	ot.O.closeEmulatorStore()
+	os.RemoveAll(ot.dir)
+}
+
+// close the sqlite store of the embedded emulator if it was started with one
+func (o *OverflowState) closeEmulatorStore() {
+	if o.emulatorStore != nil {
+		_ = o.emulatorStore.Close()
+	}
+}
+
+func SetupTest(opts []OverflowOption, setup func(o *OverflowState) error) (ot *OverflowTest, err error) {
 	allOpts := []OverflowOption{WithNetwork("testing")}
 	allOpts = append(allOpts, opts...)
 
+	dir := ""
+	if defaultOverflowBuilder.applyOptions(allOpts).IsolatedEnvironments {
+		dir, err = os.MkdirTemp("", "overflow-test")
+		if err != nil {
+			return nil, err
+		}
+		allOpts = append(allOpts, withEmulatorStore(dir, false))
+	}
+
 	o := Overflow(allOpts...)
+	if dir != "" {
+		defer func() {
+			if err != nil {
+				o.closeEmulatorStore()
+				os.RemoveAll(dir)
+			}
+		}()
+	}
 	if o.Error != nil {
 		return nil, o.Error
 	}
 
-	err := setup(o)
+	err = setup(o)
 	if err != nil {
 		return nil, err
 	}
 
 	if o.Error != nil {
-		return nil, err
+		return nil, o.Error
 	}
 
 	block, err := o.GetLatestBlock(context.Background())
@@ -77,6 +271,18 @@ func SetupTest(opts []OverflowOption, setup func(o *OverflowState) error) (*Over
 	}
 	height := block.Height
 
-	ot := &OverflowTest{O: o, height: height}
+	ot = &OverflowTest{O: o, height: height, accounts: o.registeredAccounts(), opts: opts, dir: dir}
+	if o.emulatorStore != nil {
+		// a store sent in with WithEmulatorOption replaces the sqlite store, so it does not have the state after setup
+		stored, err := o.emulatorStore.LatestBlock(context.Background())
+		if err != nil || stored.Header.Height != height {
+			return nil, fmt.Errorf("isolated environments can not be used with another emulator store, remove emulator.WithStore from the emulator options")
+		}
+		ot.snapshot = filepath.Join(dir, "setup.sqlite")
+		_, err = o.emulatorStore.DB().Exec("VACUUM main INTO ?", ot.snapshot)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not snapshot the emulator after setup")
+		}
+	}
 	return ot, nil
 }
@@ -0,0 +1,132 @@
+package overflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/flow-emulator/emulator"
+	"github.com/onflow/flow-emulator/storage/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsolatedOverflowTest(t *testing.T) {
+	ot, err := SetupTest([]OverflowOption{WithCoverageReport(), WithIsolatedEnvironments()}, func(o *OverflowState) error {
+		o.CreateAccount("alice")
+		return nil
+	})
+	require.NoError(t, err)
+
+	height := func(t *testing.T, o *OverflowState) uint64 {
+		block, err := o.GetLatestBlock(context.Background())
+		require.NoError(t, err)
+		return block.Height
+	}
+
+	var first, second *OverflowState
+	t.Run("Should get isolated states", func(t *testing.T) {
+		first = ot.Isolated(t)
+		second = ot.Isolated(t)
+		assert.NotSame(t, ot.O, first)
+		assert.NotSame(t, first, second)
+		assert.Equal(t, ot.O.Address("alice"), first.Address("alice"))
+		assert.Equal(t, ot.height, height(t, first))
+
+		first.Tx("sendFlow", WithSigner("alice"), WithArg("amount", 1.0), WithArg("to", "first")).AssertSuccess(t)
+		assert.Equal(t, ot.height+1, height(t, first))
+		assert.Equal(t, ot.height, height(t, second))
+		assert.Equal(t, ot.height, height(t, ot.O))
+	})
+	assert.Len(t, ot.pool, 2)
+
+	t.Run("Should reuse and reset state from the pool", func(t *testing.T) {
+		o := ot.Isolated(t)
+		assert.True(t, o == first || o == second)
+		assert.Equal(t, ot.height, height(t, o))
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			ot.RunParallel(t, "Should run in parallel", func(t *testing.T, o *OverflowState) {
+				assert.Equal(t, ot.height, height(t, o))
+				o.Tx("sendFlow", WithSigner("alice"), WithArg("amount", 1.0), WithArg("to", "first")).AssertSuccess(t)
+			})
+		}
+	})
+	assert.GreaterOrEqual(t, len(ot.pool), 2)
+
+	t.Run("Should report coverage below threshold", func(t *testing.T) {
+		err := ot.TeardownE(WithCoverageJSON(""), WithCoverageThreshold(101))
+		assert.ErrorContains(t, err, "below the threshold of 101.0%")
+	})
+}
+
+func TestIsolatedOverflowTestFromSetup(t *testing.T) {
+	ot, err := SetupTest([]OverflowOption{WithIsolatedEnvironments()}, func(o *OverflowState) error {
+		_, err := o.CreateAccountE(context.Background(), "bob")
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Run("Should have the account with the random key created in setup", func(t *testing.T) {
+		o := ot.Isolated(t)
+		assert.Equal(t, ot.O.Address("bob"), o.Address("bob"))
+		o.Tx("sendFlow", WithSigner("bob"), WithArg("amount", 1.0), WithArg("to", "first")).AssertSuccess(t)
+	})
+
+	t.Run("Should remove accounts created in a test on reset", func(t *testing.T) {
+		ot.Run(t, "create account", func(t *testing.T) {
+			ot.O.CreateAccount("carol")
+			assert.NotEmpty(t, ot.O.Address("carol"))
+		})
+		_, err := ot.O.AccountE("carol")
+		assert.Error(t, err)
+
+		_, err = ot.O.CreateAccountE(context.Background(), "carol")
+		assert.NoError(t, err)
+	})
+}
+
+func TestOverflowTestEmulatorStore(t *testing.T) {
+	t.Run("Should run in memory without isolated environments", func(t *testing.T) {
+		ot, err := SetupTest([]OverflowOption{}, func(o *OverflowState) error { return nil })
+		require.NoError(t, err)
+		assert.Empty(t, ot.dir)
+		assert.Nil(t, ot.O.emulatorStore)
+
+		_, err = ot.checkout()
+		assert.ErrorContains(t, err, "isolated environments need SetupTest with WithIsolatedEnvironments")
+	})
+
+	t.Run("Should remove the store files on teardown", func(t *testing.T) {
+		ot, err := SetupTest([]OverflowOption{WithIsolatedEnvironments()}, func(o *OverflowState) error { return nil })
+		require.NoError(t, err)
+		_, err = ot.checkout()
+		require.NoError(t, err)
+		require.DirExists(t, ot.dir)
+
+		require.NoError(t, ot.TeardownE())
+		assert.NoDirExists(t, ot.dir)
+	})
+
+	t.Run("Should not snapshot another emulator store", func(t *testing.T) {
+		store, err := util.CreateDefaultStorage()
+		require.NoError(t, err)
+		_, err = SetupTest([]OverflowOption{WithIsolatedEnvironments(), WithEmulatorOption(emulator.WithStore(store))}, func(o *OverflowState) error { return nil })
+		assert.ErrorContains(t, err, "isolated environments can not be used with another emulator store")
+	})
+
+	t.Run("Should remove the store files when setup fails", func(t *testing.T) {
+		before, err := filepath.Glob(filepath.Join(os.TempDir(), "overflow-test*"))
+		require.NoError(t, err)
+		_, err = SetupTest([]OverflowOption{WithIsolatedEnvironments()}, func(o *OverflowState) error { return fmt.Errorf("setup failed") })
+		assert.ErrorContains(t, err, "setup failed")
+		after, err := filepath.Glob(filepath.Join(os.TempDir(), "overflow-test*"))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, before, after)
+	})
+}
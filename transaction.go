@@ -38,6 +38,7 @@ type OverflowTransaction struct {
 	GasLimit         uint64
 	GasUsed          uint64
 	ExecutionEffort  float64
+	BalanceChanges   OverflowBalanceChanges
 }
 
 func (o *OverflowState) CreateOverflowTransaction(blockId string, transactionResult flow.TransactionResult, transaction flow.Transaction, txIndex int) (*OverflowTransaction, error) {
@@ -114,6 +115,7 @@ func (o *OverflowState) CreateOverflowTransaction(blockId string, transactionRes
 		standardStakeholders[fmt.Sprintf("0x%s", transaction.ProposalKey.Address.Hex())] = proposer
 	}
 
+	balanceChanges := events.BalanceChanges(feeAmount, fmt.Sprintf("0x%s", transaction.Payer.Hex()))
 	eventsWithoutFees := events.FilterFees(feeAmount, fmt.Sprintf("0x%s", transaction.Payer.Hex()))
 
 	eventList := []OverflowEvent{}
@@ -138,6 +140,7 @@ func (o *OverflowState) CreateOverflowTransaction(blockId string, transactionRes
 		GasLimit:         transaction.GasLimit,
 		GasUsed:          uint64(gas),
 		ExecutionEffort:  executionEffort,
+		BalanceChanges:   balanceChanges,
 		Authorizers:      authorizers,
 		AuthorizerTypes:  authorizerTypes,
 	}, nil
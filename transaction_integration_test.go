@@ -41,6 +41,7 @@ func TestTransactionIntegration(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.Equal(t, []string{"BorrowValue"}, oTx.AuthorizerTypes["0xf8d6e0586b0a20c7"])
+		assert.Equal(t, 100.1, oTx.BalanceChanges.Get(o.Address("first"), "FlowToken.Vault"))
 	})
 
 	t.Run("fail on missing signer", func(t *testing.T) {
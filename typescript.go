@@ -0,0 +1,349 @@
+package overflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser"
+)
+
+// TypeScript module
+//
+// A merged solution can be turned into a javascript module with one fcl wrapper for each script and transaction in every network,
+// together with typescript declarations for the arguments and return values
+//
+//	import { emulator } from "./interactions"
+//	const balance = await emulator.scripts.get_balance({ account: "0xf8d6e0586b0a20c7" })
+
+// a generated typescript module
+type OverflowTypeScriptModule struct {
+	// the content of index.d.ts
+	Declarations string
+
+	// the content of index.js
+	Module string
+
+	// interactions that could not be generated
+	Warnings []string
+}
+
+// a cadence type as a typescript type and as an fcl type
+type typeScriptType struct {
+	Input  string
+	Output string
+	Fcl    string
+}
+
+var typeScriptIntegerTypes = map[string]bool{
+	"Int": true, "Int8": true, "Int16": true, "Int32": true, "Int64": true, "Int128": true, "Int256": true,
+	"UInt": true, "UInt8": true, "UInt16": true, "UInt32": true, "UInt64": true, "UInt128": true, "UInt256": true,
+	"Word8": true, "Word16": true, "Word32": true, "Word64": true, "Word128": true, "Word256": true,
+}
+
+var typeScriptPathTypes = map[string]bool{
+	"Path": true, "StoragePath": true, "PublicPath": true, "PrivatePath": true, "CapabilityPath": true,
+}
+
+// the typescript and fcl types for a cadence type, false if the type can not be sent as an argument
+func typeScriptTypeOf(cadenceType ast.Type) (typeScriptType, bool) {
+	switch t := cadenceType.(type) {
+	case *ast.NominalType:
+		if len(t.NestedIdentifiers) > 0 {
+			return typeScriptType{}, false
+		}
+		name := t.Identifier.Identifier
+		switch {
+		case typeScriptIntegerTypes[name]:
+			return typeScriptType{Input: "string | number", Output: "number", Fcl: "t." + name}, true
+		case name == "Fix64" || name == "UFix64":
+			return typeScriptType{Input: "string", Output: "number", Fcl: "t." + name}, true
+		case name == "String" || name == "Character" || name == "Address":
+			return typeScriptType{Input: "string", Output: "string", Fcl: "t." + name}, true
+		case name == "Bool":
+			return typeScriptType{Input: "boolean", Output: "boolean", Fcl: "t.Bool"}, true
+		case typeScriptPathTypes[name]:
+			return typeScriptType{Input: "Path", Output: "Path", Fcl: "t.Path"}, true
+		}
+	case *ast.OptionalType:
+		inner, ok := typeScriptTypeOf(t.Type)
+		if !ok {
+			return typeScriptType{}, false
+		}
+		return typeScriptType{
+			Input:  fmt.Sprintf("%s | null", inner.Input),
+			Output: fmt.Sprintf("%s | null", inner.Output),
+			Fcl:    fmt.Sprintf("t.Optional(%s)", inner.Fcl),
+		}, true
+	case *ast.VariableSizedType:
+		return typeScriptArrayType(t.Type)
+	case *ast.ConstantSizedType:
+		return typeScriptArrayType(t.Type)
+	case *ast.DictionaryType:
+		key, ok := typeScriptTypeOf(t.KeyType)
+		if !ok {
+			return typeScriptType{}, false
+		}
+		value, ok := typeScriptTypeOf(t.ValueType)
+		if !ok {
+			return typeScriptType{}, false
+		}
+		return typeScriptType{
+			Input:  fmt.Sprintf("{ key: %s; value: %s }[]", key.Input, value.Input),
+			Output: fmt.Sprintf("Record<string, %s>", value.Output),
+			Fcl:    fmt.Sprintf("t.Dictionary({ key: %s, value: %s })", key.Fcl, value.Fcl),
+		}, true
+	}
+	return typeScriptType{}, false
+}
+
+func typeScriptArrayType(element ast.Type) (typeScriptType, bool) {
+	inner, ok := typeScriptTypeOf(element)
+	if !ok {
+		return typeScriptType{}, false
+	}
+	return typeScriptType{
+		Input:  typeScriptArrayOf(inner.Input),
+		Output: typeScriptArrayOf(inner.Output),
+		Fcl:    fmt.Sprintf("t.Array(%s)", inner.Fcl),
+	}, true
+}
+
+// an array of a typescript type, union types need parentheses
+func typeScriptArrayOf(element string) string {
+	if strings.Contains(element, " ") {
+		return fmt.Sprintf("(%s)[]", element)
+	}
+	return element + "[]"
+}
+
+// the typescript type of a type written as a string in a declaration info
+func typeScriptTypeOfString(cadenceType string) (typeScriptType, bool) {
+	parsed, errs := parser.ParseType(nil, []byte(cadenceType), parser.Config{})
+	if len(errs) > 0 {
+		return typeScriptType{}, false
+	}
+	return typeScriptTypeOf(parsed)
+}
+
+// the typescript type returned by the main function of a script, any if it can not be mapped
+func scriptReturnType(code string) string {
+	cadenceType := returnType([]byte(code))
+	if cadenceType == "" {
+		return "null"
+	}
+	typeScriptReturnType, ok := typeScriptTypeOfString(cadenceType)
+	if !ok {
+		return "any"
+	}
+	return typeScriptReturnType.Output
+}
+
+// a string as a javascript literal
+func jsString(value string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+var nonIdentifierCharacters = regexp.MustCompile(`[^A-Za-z0-9_$]`)
+
+// a network name as a javascript identifier
+func jsIdentifier(name string) string {
+	identifier := nonIdentifierCharacters.ReplaceAllString(name, "_")
+	if identifier == "" || (identifier[0] >= '0' && identifier[0] <= '9') {
+		identifier = "_" + identifier
+	}
+	return identifier
+}
+
+// the arguments of an interaction as a typescript type and an fcl args function
+func typeScriptArguments(spec *OverflowDeclarationInfo) (string, string, error) {
+	if spec == nil || len(spec.ParameterOrder) == 0 {
+		return "args?: Record<string, never>", "(arg, t) => []", nil
+	}
+	fields := []string{}
+	args := []string{}
+	for _, name := range spec.ParameterOrder {
+		argumentType, ok := typeScriptTypeOfString(spec.Parameters[name])
+		if !ok {
+			return "", "", fmt.Errorf("parameter %s has unsupported type %s", name, spec.Parameters[name])
+		}
+		fields = append(fields, fmt.Sprintf("%s: %s", jsString(name), argumentType.Input))
+		args = append(args, fmt.Sprintf("arg(args[%s], %s)", jsString(name), argumentType.Fcl))
+	}
+	return fmt.Sprintf("args: { %s }", strings.Join(fields, "; ")), fmt.Sprintf("(arg, t) => [%s]", strings.Join(args, ", ")), nil
+}
+
+// a jsdoc comment for the doc comment of an interaction, parameters are described as properties of args
+func jsDocComment(spec *OverflowDeclarationInfo) string {
+	if spec == nil || spec.Doc == nil {
+		return ""
+	}
+	doc := spec.Doc
+	lines := []string{}
+	for _, paragraph := range []string{doc.Title, doc.Description} {
+		if paragraph == "" {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.Split(paragraph, "\n")...)
+	}
+	for _, parameter := range spec.ParameterOrder {
+		if description, ok := doc.Parameters[parameter]; ok {
+			lines = append(lines, fmt.Sprintf("@param args.%s %s", parameter, description))
+		}
+	}
+	tags := []string{}
+	for tag := range doc.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		lines = append(lines, fmt.Sprintf("@%s %s", tag, doc.Tags[tag]))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintln(&sb, "    /**")
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, "*/", "*\\/")
+		fmt.Fprintln(&sb, strings.TrimRight("     * "+line, " "))
+	}
+	fmt.Fprintln(&sb, "     */")
+	return sb.String()
+}
+
+func sortedInteractionNames(interactions map[string]OverflowCodeWithSpec) []string {
+	names := []string{}
+	for name := range interactions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GenerateTypeScript creates a javascript module with fcl wrappers for all scripts and transactions in every network and the typescript declarations for it
+// Interactions with parameters that can not be sent from fcl are skipped with a warning
+func (s *OverflowSolutionMerged) GenerateTypeScript() *OverflowTypeScriptModule {
+	networkNames := []string{}
+	for name := range s.Networks {
+		networkNames = append(networkNames, name)
+	}
+	sort.Strings(networkNames)
+
+	var declarations strings.Builder
+	var module strings.Builder
+	warnings := []string{}
+
+	fmt.Fprintln(&declarations, "// Code generated by overflow. DO NOT EDIT.")
+	fmt.Fprintln(&declarations)
+	fmt.Fprintln(&declarations, "export type Path = { domain: string; identifier: string };")
+	fmt.Fprintln(&declarations)
+	fmt.Fprintln(&declarations, "export type TransactionOptions = { limit?: number; proposer?: unknown; payer?: unknown; authorizations?: unknown[] };")
+
+	fmt.Fprintln(&module, "// Code generated by overflow. DO NOT EDIT.")
+	fmt.Fprintln(&module)
+	fmt.Fprintln(&module, `import * as fcl from "@onflow/fcl";`)
+
+	for _, networkName := range networkNames {
+		network := s.Networks[networkName]
+		identifier := jsIdentifier(networkName)
+
+		fmt.Fprintln(&declarations)
+		fmt.Fprintf(&declarations, "export declare const %s: {\n", identifier)
+		fmt.Fprintln(&module)
+		fmt.Fprintf(&module, "export const %s = {\n", identifier)
+
+		fmt.Fprintln(&declarations, "  scripts: {")
+		fmt.Fprintln(&module, "  scripts: {")
+		for _, name := range sortedInteractionNames(network.Scripts) {
+			script := network.Scripts[name]
+			argumentType, args, err := typeScriptArguments(script.Spec)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("skipping script %s in network %s: %s", name, networkName, err.Error()))
+				continue
+			}
+			declarations.WriteString(jsDocComment(script.Spec))
+			fmt.Fprintf(&declarations, "    %s: (%s) => Promise<%s>;\n", jsString(name), argumentType, scriptReturnType(script.Code))
+			fmt.Fprintf(&module, "    %s: (args) => fcl.query({\n", jsString(name))
+			fmt.Fprintf(&module, "      cadence: %s,\n", jsString(script.Code))
+			fmt.Fprintf(&module, "      args: %s,\n", args)
+			fmt.Fprintln(&module, "    }),")
+		}
+		fmt.Fprintln(&declarations, "  };")
+		fmt.Fprintln(&module, "  },")
+
+		fmt.Fprintln(&declarations, "  transactions: {")
+		fmt.Fprintln(&module, "  transactions: {")
+		for _, name := range sortedInteractionNames(network.Transactions) {
+			transaction := network.Transactions[name]
+			argumentType, args, err := typeScriptArguments(transaction.Spec)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("skipping transaction %s in network %s: %s", name, networkName, err.Error()))
+				continue
+			}
+			authorizations := []string{}
+			if transaction.Spec != nil {
+				for range transaction.Spec.Authorizers {
+					authorizations = append(authorizations, "fcl.authz")
+				}
+			}
+			declarations.WriteString(jsDocComment(transaction.Spec))
+			fmt.Fprintf(&declarations, "    %s: (%s, options?: TransactionOptions) => Promise<string>;\n", jsString(name), argumentType)
+			fmt.Fprintf(&module, "    %s: (args, options = {}) => fcl.mutate({\n", jsString(name))
+			fmt.Fprintf(&module, "      cadence: %s,\n", jsString(transaction.Code))
+			fmt.Fprintf(&module, "      args: %s,\n", args)
+			fmt.Fprintf(&module, "      authorizations: [%s],\n", strings.Join(authorizations, ", "))
+			fmt.Fprintln(&module, "      limit: 9999,")
+			fmt.Fprintln(&module, "      ...options,")
+			fmt.Fprintln(&module, "    }),")
+		}
+		fmt.Fprintln(&declarations, "  };")
+		fmt.Fprintln(&module, "  },")
+
+		fmt.Fprintln(&declarations, "};")
+		fmt.Fprintln(&module, "};")
+	}
+
+	networks := []string{}
+	for _, networkName := range networkNames {
+		networks = append(networks, fmt.Sprintf("%s: %s", jsString(networkName), jsIdentifier(networkName)))
+	}
+	declarationNetworks := []string{}
+	for _, networkName := range networkNames {
+		declarationNetworks = append(declarationNetworks, fmt.Sprintf("%s: typeof %s", jsString(networkName), jsIdentifier(networkName)))
+	}
+	fmt.Fprintln(&declarations)
+	fmt.Fprintf(&declarations, "export declare const networks: { %s };\n", strings.Join(declarationNetworks, "; "))
+	fmt.Fprintln(&module)
+	fmt.Fprintf(&module, "export const networks = { %s };\n", strings.Join(networks, ", "))
+
+	return &OverflowTypeScriptModule{
+		Declarations: declarations.String(),
+		Module:       module.String(),
+		Warnings:     warnings,
+	}
+}
+
+// Write writes the module as index.js and index.d.ts to the given directory
+func (m *OverflowTypeScriptModule) Write(dir string) error {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dir, "index.js"), []byte(m.Module), 0o644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "index.d.ts"), []byte(m.Declarations), 0o644)
+}
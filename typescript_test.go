@@ -0,0 +1,118 @@
+package overflow
+
+import (
+	"testing"
+
+	"github.com/hexops/autogold"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTypeScript(t *testing.T) {
+	solution := &OverflowSolutionMerged{
+		Networks: map[string]OverflowSolutionMergedNetwork{
+			"emulator": {
+				Scripts: map[string]OverflowCodeWithSpec{
+					"balance": {
+						Code: "access(all) fun main(account: Address, paths: [StoragePath]): {String: UFix64} {\n    return {}\n}",
+						Spec: &OverflowDeclarationInfo{
+							ParameterOrder: []string{"account", "paths"},
+							Parameters:     map[string]string{"account": "Address", "paths": "[StoragePath]"},
+						},
+					},
+					"resource": {
+						Code: "access(all) fun main(nft: @NonFungibleToken.NFT) {}",
+						Spec: &OverflowDeclarationInfo{
+							ParameterOrder: []string{"nft"},
+							Parameters:     map[string]string{"nft": "@NonFungibleToken.NFT"},
+						},
+					},
+				},
+				Transactions: map[string]OverflowCodeWithSpec{
+					"mint": {
+						Code: "transaction(amount: UInt64, memo: String?) {\n    prepare(signer: &Account) {}\n}",
+						Spec: &OverflowDeclarationInfo{
+							ParameterOrder: []string{"amount", "memo"},
+							Parameters:     map[string]string{"amount": "UInt64", "memo": "String?"},
+							Authorizers:    OverflowAuthorizers{{}},
+							Doc: &OverflowDeclarationDoc{
+								Title:       "Mint tokens",
+								Description: "Mints new tokens to the signer",
+								Parameters:  map[string]string{"amount": "the amount to mint"},
+								Tags:        map[string]string{"deprecated": "use mint_v2"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	result := solution.GenerateTypeScript()
+	autogold.Want("declarations", `// Code generated by overflow. DO NOT EDIT.
+
+export type Path = { domain: string; identifier: string };
+
+export type TransactionOptions = { limit?: number; proposer?: unknown; payer?: unknown; authorizations?: unknown[] };
+
+export declare const emulator: {
+  scripts: {
+    "balance": (args: { "account": string; "paths": Path[] }) => Promise<Record<string, number>>;
+  };
+  transactions: {
+    /**
+     * Mint tokens
+     *
+     * Mints new tokens to the signer
+     * @param args.amount the amount to mint
+     * @deprecated use mint_v2
+     */
+    "mint": (args: { "amount": string | number; "memo": string | null }, options?: TransactionOptions) => Promise<string>;
+  };
+};
+
+export declare const networks: { "emulator": typeof emulator };
+`).Equal(t, result.Declarations)
+	autogold.Want("module", `// Code generated by overflow. DO NOT EDIT.
+
+import * as fcl from "@onflow/fcl";
+
+export const emulator = {
+  scripts: {
+    "balance": (args) => fcl.query({
+      cadence: "access(all) fun main(account: Address, paths: [StoragePath]): {String: UFix64} {\n    return {}\n}",
+      args: (arg, t) => [arg(args["account"], t.Address), arg(args["paths"], t.Array(t.Path))],
+    }),
+  },
+  transactions: {
+    "mint": (args, options = {}) => fcl.mutate({
+      cadence: "transaction(amount: UInt64, memo: String?) {\n    prepare(signer: &Account) {}\n}",
+      args: (arg, t) => [arg(args["amount"], t.UInt64), arg(args["memo"], t.Optional(t.String))],
+      authorizations: [fcl.authz],
+      limit: 9999,
+      ...options,
+    }),
+  },
+};
+
+export const networks = { "emulator": emulator };
+`).Equal(t, result.Module)
+	assert.Equal(t, []string{"skipping script resource in network emulator: parameter nft has unsupported type @NonFungibleToken.NFT"}, result.Warnings)
+}
+
+func TestGenerateTypeScriptFromProject(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	solution, err := o.ParseAll()
+	require.NoError(t, err)
+
+	result := solution.MergeSpecAndCode().GenerateTypeScript()
+	assert.Contains(t, result.Declarations, "export declare const emulator")
+	assert.Contains(t, result.Module, "fcl.mutate")
+
+	dir := t.TempDir()
+	require.NoError(t, result.Write(dir))
+	assert.FileExists(t, dir+"/index.d.ts")
+	assert.FileExists(t, dir+"/index.js")
+}
@@ -1,9 +1,11 @@
 package overflow
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTransactionUpload(t *testing.T) {
@@ -130,3 +132,103 @@ transaction {
 
 	})
 }
+
+func TestStorageBlobs(t *testing.T) {
+	o, err := OverflowTesting()
+	require.NoError(t, err)
+
+	o.Tx("mint_tokens",
+		WithSignerServiceAccount(),
+		WithArg("recipient", "first"),
+		WithArg("amount", 1000.0),
+	).AssertSuccess(t)
+
+	t.Run("bytes round trip in chunks", func(t *testing.T) {
+		content := make([]byte, uploadBytesChunkSize+1000)
+		for i := range content {
+			content[i] = byte(i % 256)
+		}
+		require.NoError(t, o.UploadBytesToPath(content, "first", "/storage/art"))
+
+		downloaded, err := o.DownloadBytesFromPath("first", "/storage/art")
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("string round trip", func(t *testing.T) {
+		content := "generative art 🎨 with ünïcode"
+		require.NoError(t, o.UploadStringToPath(content, "first", "/storage/text"))
+
+		downloaded, err := o.DownloadStringFromPath("first", "/storage/text")
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("string with multi-byte runes round trip in chunks", func(t *testing.T) {
+		// the one byte prefix puts the end of the first chunk inside a rune
+		content := "a" + strings.Repeat("🎨", uploadStringChunkSize/4+100)
+		require.Equal(t, uploadStringChunkSize-3, len(utf8Prefix(content, uploadStringChunkSize)))
+		require.NoError(t, o.UploadStringToPath(content, "first", "/storage/runes"))
+
+		downloaded, err := o.DownloadStringFromPath("first", "/storage/runes")
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		require.NoError(t, o.UploadBytesToPath([]byte{}, "first", "/storage/empty"))
+
+		downloaded, err := o.DownloadBytesFromPath("first", "/storage/empty")
+		require.NoError(t, err)
+		assert.Empty(t, downloaded)
+	})
+
+	t.Run("resume an upload that stopped halfway", func(t *testing.T) {
+		content := []byte("the first half and the second half")
+		require.NoError(t, o.UploadBytesToPath(content[:14], "first", "/storage/resume"))
+
+		stored, err := o.storedBlob("first", "/storage/resume")
+		require.NoError(t, err)
+		assert.Equal(t, &storedBlob{Type: "[UInt8]", Length: 14, Hash: sha3Hex(string(content[:14]))}, stored)
+
+		require.NoError(t, o.UploadBytesToPath(content, "first", "/storage/resume"))
+		downloaded, err := o.DownloadBytesFromPath("first", "/storage/resume")
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("replace other content", func(t *testing.T) {
+		require.NoError(t, o.UploadStringToPath("some old text", "first", "/storage/replace"))
+		require.NoError(t, o.UploadBytesToPath([]byte{1, 2, 3}, "first", "/storage/replace"))
+
+		downloaded, err := o.DownloadBytesFromPath("first", "/storage/replace")
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 3}, downloaded)
+	})
+
+	t.Run("upload string to /storage/upload", func(t *testing.T) {
+		require.NoError(t, o.UploadString("first version", "first"))
+		require.NoError(t, o.UploadString("second", "first"))
+
+		downloaded, err := o.DownloadStringFromPath("first", "/storage/upload")
+		require.NoError(t, err)
+		assert.Equal(t, "second", downloaded)
+	})
+
+	t.Run("do not replace values that were not uploaded", func(t *testing.T) {
+		o.Tx(`
+transaction {
+	prepare(signer: auth(SaveValue) &Account) {
+		signer.storage.save(42, to: /storage/number)
+	}
+}`, WithSigner("first")).AssertSuccess(t)
+
+		err := o.UploadStringToPath("text", "first", "/storage/number")
+		assert.ErrorContains(t, err, "there is no uploaded content at /storage/number")
+	})
+
+	t.Run("download from an empty path", func(t *testing.T) {
+		_, err := o.DownloadBytesFromPath("first", "/storage/nothing")
+		assert.ErrorContains(t, err, "there is no String or [UInt8] at /storage/nothing")
+	})
+}
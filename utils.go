@@ -16,6 +16,7 @@ import (
 
 	"github.com/araddon/dateparse"
 	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk/crypto"
 )
 
 // go string to cadence string panic if error
@@ -149,6 +150,11 @@ func randomString(length int) string {
 	return fmt.Sprintf("%x", b)[:length]
 }
 
+// the hex encoded SHA3-256 hash of a value
+func sha3Hex(value string) string {
+	return hex.EncodeToString(crypto.NewSHA3_256().ComputeHash([]byte(value)))
+}
+
 // HexToAddress converts a hex string to an Address.
 func hexToAddress(h string) (*cadence.Address, error) {
 	trimmed := strings.TrimPrefix(h, "0x")